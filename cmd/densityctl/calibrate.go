@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/LglzNL/density/internal/bench"
+	"github.com/LglzNL/density/internal/ksm"
+)
+
+// cmdBenchCalibrate misst das Grundrauschen des ruhenden Hosts und legt es
+// für spätere Bench-Runs ab.
+func cmdBenchCalibrate(args []string) error {
+	fs := flag.NewFlagSet("bench calibrate", flag.ContinueOnError)
+	var (
+		ksmPath   = fs.String("ksm-path", ksm.DefaultPath, "KSM sysfs Pfad")
+		durationS = fs.Int("duration-sec", 180, "Messdauer in Sekunden")
+		intervalM = fs.Int("interval-ms", 1000, "Abtastintervall in Millisekunden")
+		outDir    = fs.String("out", "results", "Output-Verzeichnis")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	fmt.Printf("Kalibriere %ds lang – bitte den Host in Ruhe lassen ...\n", *durationS)
+	cal, err := bench.Calibrate(context.Background(), bench.CalibrateConfig{
+		KSMPath:  *ksmPath,
+		Duration: time.Duration(*durationS) * time.Second,
+		Interval: time.Duration(*intervalM) * time.Millisecond,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(*outDir, 0o755); err != nil {
+		return err
+	}
+	path := filepath.Join(*outDir, bench.CalibrationFile)
+	if err := bench.SaveCalibration(path, cal); err != nil {
+		return err
+	}
+
+	fmt.Printf("  Samples:                 %d\n", cal.Samples)
+	fmt.Printf("  MemAvailable σ / Spanne: %.1f / %.1f MiB\n", cal.MemAvailStddevMiB, cal.MemAvailRangeMiB)
+	fmt.Printf("  ksmd ticks/s (Leerlauf): %.2f\n", cal.KsmdTicksPerSec)
+	if cal.FullScanSec > 0 {
+		fmt.Printf("  Dauer voller Scan:       %.1fs\n", cal.FullScanSec)
+	}
+	fmt.Printf("  Rauschuntergrenze:       ±%.1f MiB\n", cal.NoiseFloorMiB())
+	fmt.Printf("OK: Kalibrierung gespeichert: %s\n", path)
+	return nil
+}
+
+// loadCalibration lädt eine explizit angegebene Kalibrierung oder – falls vorhanden –
+// die Default-Datei im Output-Verzeichnis.
+func loadCalibration(path, outDir string) (*bench.Calibration, error) {
+	if path != "" {
+		return bench.LoadCalibration(path)
+	}
+	cal, err := bench.LoadCalibration(filepath.Join(outDir, bench.CalibrationFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return cal, err
+}
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/LglzNL/density/internal/history"
+	"github.com/LglzNL/density/internal/ksm"
+	"github.com/LglzNL/density/internal/results"
+)
+
+// cmdHistory: Sample-Historie aufzeichnen und auswerten.
+func cmdHistory(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("bitte Unterbefehl angeben: history record|heatmap|knobs")
+	}
+	switch args[0] {
+	case "record":
+		return cmdHistoryRecord(args[1:])
+	case "heatmap":
+		return cmdHistoryHeatmap(args[1:])
+	case "knobs":
+		return cmdHistoryKnobs(args[1:])
+	default:
+		return fmt.Errorf("unbekannter history-Unterbefehl: %s", args[0])
+	}
+}
+
+func cmdHistoryRecord(args []string) error {
+	fs := flag.NewFlagSet("history record", flag.ContinueOnError)
+	var (
+		ksmPath  = fs.String("ksm-path", ksm.DefaultPath, "KSM sysfs Pfad")
+		path     = fs.String("path", history.DefaultPath, "Historien-Datei (JSON Lines)")
+		interval = fs.Int("interval-sec", 60, "Abtastintervall in Sekunden")
+		count    = fs.Int("count", 0, "Nach so vielen Samples beenden (0 = bis SIGINT/SIGTERM)")
+		knobLog  = fs.String("knob-log", history.DefaultKnobsPath, "Knob-Änderungsprotokoll (Drift wird dort vermerkt)")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *interval <= 0 {
+		return fmt.Errorf("interval-sec muss > 0 sein")
+	}
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+
+	t := time.NewTicker(time.Duration(*interval) * time.Second)
+	defer t.Stop()
+
+	for n := 1; ; n++ {
+		s := history.Collect(*ksmPath)
+		if err := history.Append(*path, s); err != nil {
+			return err
+		}
+		if err := recordDrift(*knobLog, s); err != nil {
+			fmt.Fprintf(os.Stderr, "Warnung: Knob-Protokoll: %v\n", err)
+		}
+		if *count > 0 && n >= *count {
+			break
+		}
+		select {
+		case <-sigCh:
+			fmt.Printf("OK: %d Samples nach %s geschrieben.\n", n, *path)
+			return nil
+		case <-t.C:
+		}
+	}
+	fmt.Printf("OK: %d Samples nach %s geschrieben.\n", *count, *path)
+	return nil
+}
+
+func cmdHistoryHeatmap(args []string) error {
+	fs := flag.NewFlagSet("history heatmap", flag.ContinueOnError)
+	var (
+		path   = fs.String("path", history.DefaultPath, "Historien-Datei (JSON Lines)")
+		metric = fs.String("metric", "saved", "Metrik: saved|ksmd-cpu")
+		since  = fs.String("since", "30d", "Zeitraum, z.B. 30d, 2w")
+		format = fs.String("format", "ascii", "Ausgabe: ascii|markdown|json")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	from, err := sinceTime(*since)
+	if err != nil {
+		return err
+	}
+	samples, err := history.Read(*path, from)
+	if err != nil {
+		return err
+	}
+	if len(samples) == 0 {
+		return fmt.Errorf("keine Samples in %s (erst 'history record' oder den Daemon laufen lassen)", *path)
+	}
+
+	h, err := history.BuildHeatmap(samples, history.Metric(*metric), time.Local)
+	if err != nil {
+		return err
+	}
+	switch *format {
+	case "ascii":
+		fmt.Print(h.RenderASCII())
+	case "markdown":
+		fmt.Print(h.RenderMarkdown())
+	case "json":
+		b, _ := json.MarshalIndent(struct {
+			Metric history.Metric  `json:"metric"`
+			Grid   [7][24]*float64 `json:"grid"`
+			Counts [7][24]int      `json:"counts"`
+		}{h.Metric, h.Grid(), h.Counts}, "", "  ")
+		fmt.Println(string(b))
+	default:
+		return fmt.Errorf("unbekanntes Format %q (ascii|markdown|json)", *format)
+	}
+	return nil
+}
+
+// recordDrift gleicht einen Sample mit dem Knob-Protokoll ab. Das Protokoll wird jedes Mal
+// neu gelesen, damit Änderungen von enable/disable nicht zusätzlich als Drift erscheinen.
+func recordDrift(knobLog string, s history.Sample) error {
+	changes, err := history.ReadKnobChanges(knobLog, time.Time{})
+	if err != nil {
+		return err
+	}
+	known := history.KnobStateAt(changes, s.Time)
+	return history.AppendKnobChanges(knobLog, history.DetectDrift(known, s, ksm.Knobs)...)
+}
+
+// recordKnobChanges protokolliert, was ein DENSITY-Befehl an den Knobs geändert hat.
+// Weicht der Vorher-Stand vom Protokoll ab, wird das zuerst als Drift vermerkt, damit
+// die Zeitleiste lückenlos bleibt. Fehler (z.B. fehlende Rechte) sind nur Warnungen.
+func recordKnobChanges(knobLog string, before, after map[string]int64, source string) {
+	now := time.Now()
+	logged, err := history.ReadKnobChanges(knobLog, time.Time{})
+	if err == nil {
+		drift := history.DetectDrift(history.KnobStateAt(logged, now), history.Sample{Time: now, KSM: before}, ksm.Knobs)
+		err = history.AppendKnobChanges(knobLog, append(drift, history.DiffKnobs(before, after, source, now)...)...)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warnung: Knob-Protokoll: %v\n", err)
+	}
+}
+
+func cmdHistoryKnobs(args []string) error {
+	if len(args) > 0 && args[0] == "diff" {
+		return cmdHistoryKnobsDiff(args[1:])
+	}
+
+	fs := flag.NewFlagSet("history knobs", flag.ContinueOnError)
+	var (
+		knobLog = fs.String("knob-log", history.DefaultKnobsPath, "Knob-Änderungsprotokoll")
+		samples = fs.String("path", history.DefaultPath, "Sample-Historie (für Ersparnis vorher/nachher)")
+		since   = fs.String("since", "30d", "Zeitraum, z.B. 30d, 2w")
+		knob    = fs.String("knob", "", "Nur diesen Knob anzeigen")
+		window  = fs.Duration("window", time.Hour, "Fenster für Ersparnis vor/nach einer Änderung")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	from, err := sinceTime(*since)
+	if err != nil {
+		return err
+	}
+	changes, err := history.ReadKnobChanges(*knobLog, from)
+	if err != nil {
+		return err
+	}
+	hist, err := history.Read(*samples, from.Add(-*window))
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ZEIT\tKNOB\tALT → NEU\tQUELLE\tSAVED VORHER\tSAVED NACHHER\tΔ MiB")
+	for _, c := range changes {
+		if *knob != "" && c.Knob != *knob {
+			continue
+		}
+		old := "?"
+		if c.Old != nil {
+			old = strconv.FormatInt(*c.Old, 10)
+		}
+		corr := "-\t-\t-"
+		if b, a, ok := history.SavedAround(hist, c.Time, *window); ok {
+			corr = fmt.Sprintf("%.1f\t%.1f\t%+.1f", b, a, a-b)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s → %d\t%s\t%s\n",
+			c.Time.Local().Format("2006-01-02 15:04:05"), c.Knob, old, c.New, c.Source, corr)
+	}
+	return tw.Flush()
+}
+
+// cmdHistoryKnobsDiff zeigt, welche Knobs sich zwischen zwei Zeitpunkten unterscheiden.
+func cmdHistoryKnobsDiff(args []string) error {
+	fs := flag.NewFlagSet("history knobs diff", flag.ContinueOnError)
+	var (
+		knobLog = fs.String("knob-log", history.DefaultKnobsPath, "Knob-Änderungsprotokoll")
+		from    = fs.String("from", "7d", "Älterer Zeitpunkt: RFC3339 oder Alter (z.B. 7d)")
+		to      = fs.String("to", "0s", "Neuerer Zeitpunkt: RFC3339 oder Alter (0s = jetzt)")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	tFrom, err := parsePointInTime(*from)
+	if err != nil {
+		return err
+	}
+	tTo, err := parsePointInTime(*to)
+	if err != nil {
+		return err
+	}
+	changes, err := history.ReadKnobChanges(*knobLog, time.Time{})
+	if err != nil {
+		return err
+	}
+	a, b := history.KnobStateAt(changes, tFrom), history.KnobStateAt(changes, tTo)
+
+	fmt.Printf("Knobs %s → %s\n", tFrom.Local().Format(time.RFC3339), tTo.Local().Format(time.RFC3339))
+	diff := history.DiffKnobs(a, b, "", tTo)
+	if len(diff) == 0 {
+		fmt.Println("  keine Unterschiede")
+		return nil
+	}
+	for _, c := range diff {
+		old := "?"
+		if c.Old != nil {
+			old = strconv.FormatInt(*c.Old, 10)
+		}
+		fmt.Printf("  %-36s %s → %d\n", c.Knob, old, c.New)
+	}
+	return nil
+}
+
+// sinceTime wandelt eine Altersangabe in einen Startzeitpunkt (Zero = unbegrenzt).
+func sinceTime(age string) (time.Time, error) {
+	d, err := results.ParseAge(age)
+	if err != nil || d == 0 {
+		return time.Time{}, err
+	}
+	return time.Now().Add(-d), nil
+}
+
+// parsePointInTime akzeptiert RFC3339 oder eine Altersangabe relativ zu jetzt.
+func parsePointInTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	d, err := results.ParseAge(s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Now().Add(-d), nil
+}
@@ -0,0 +1,399 @@
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/LglzNL/density/internal/bench"
+	"github.com/LglzNL/density/internal/ksm"
+)
+
+// cmdHog ist ein kontrollierter RAM-Allocator für Benchmarks.
+// Er erzeugt (auf Wunsch) identische Pages zwischen Prozessen (gut für P1/P2) und kann Pages gezielt \"verschmutzen\" (P2/P3).
+func cmdHog(args []string) error {
+	fs := flag.NewFlagSet("__hog", flag.ContinueOnError)
+	var (
+		memMiB    = fs.Int("mem-mib", 256, "Allokation (MiB)")
+		id        = fs.Int("id", 0, "Instanz-ID")
+		dirtyPct  = fs.Float64("dirty-pct", 0, "Prozent der Pages, die pro Instanz individuell gemacht werden (0..100)")
+		redirtyMs = fs.Int("redirty-ms", 0, "Wenn >0: alle X ms werden die individuellen Pages erneut beschrieben (verhindert Merge)")
+		pattern   = fs.String("pattern", bench.PatternConst, "Inhaltsmuster: const|zero|random")
+		seed      = fs.Int("seed", 0, "Inhalts-Seed: 0 = gemeinsamer Basis-Inhalt, sonst instanzeigen")
+		workers   = fs.Int("workers", 1, "Redirty: Anzahl Goroutinen (je ein Chunk der individuellen Pages)")
+		jitterPct = fs.Float64("jitter-pct", 0, "Redirty: zufällige Abweichung des Intervalls pro Runde (0..100 %)")
+		ratePPS   = fs.Int("rate-pps", 0, "Redirty: max. Pages pro Sekunde über alle Goroutinen (0 = unbegrenzt)")
+		reportFD  = fs.Int("report-fd", 0, "Wenn >0: Selbstauskunft (JSON-Zeilen) auf diesen File-Descriptor schreiben")
+		reportMs  = fs.Int("report-ms", 1000, "Intervall der Selbstauskunft (ms)")
+		growTo    = fs.String("grow-to", "", "Ballooning: MIB@DAUER, z.B. 512@10s – nach DAUER auf MIB wachsen")
+		shrinkTo  = fs.String("shrink-to", "", "Ballooning: MIB@DAUER – nach DAUER auf MIB schrumpfen (MADV_DONTNEED)")
+		mode      = fs.String("mode", bench.ModeAnon, "Speichermodus: anon (KSM) | file-shared (Kontrollgruppe, read-only MAP_SHARED)")
+		shared    = fs.String("shared-file", "", "Modus file-shared: gemeinsame Datei, die gemappt wird")
+		forkK     = fs.Int("fork-children", 0, "Nach der Allokation K Kinder forken (Copy-on-Write-Sharing ab Start)")
+		madv      = fs.String("madvise", "", "madvise-Hinweise, Komma-Liste (mergeable, dontfork, wipeonfork, ...)")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *memMiB <= 0 {
+		return fmt.Errorf("mem-mib muss > 0 sein")
+	}
+	if *dirtyPct < 0 || *dirtyPct > 100 {
+		return fmt.Errorf("dirty-pct muss 0..100 sein")
+	}
+	if *workers <= 0 {
+		return fmt.Errorf("workers muss > 0 sein")
+	}
+	if *jitterPct < 0 || *jitterPct > 100 {
+		return fmt.Errorf("jitter-pct muss 0..100 sein")
+	}
+	if *ratePPS < 0 {
+		return fmt.Errorf("rate-pps muss >= 0 sein")
+	}
+
+	var spec bench.HogSpec
+	var err error
+	if *growTo != "" {
+		if spec.GrowTo, err = bench.ParseBalloon(*growTo); err != nil {
+			return err
+		}
+	}
+	if *shrinkTo != "" {
+		if spec.ShrinkTo, err = bench.ParseBalloon(*shrinkTo); err != nil {
+			return err
+		}
+	}
+	if err := spec.ValidateBalloon(*memMiB, math.MaxInt64); err != nil {
+		return err
+	}
+
+	if *forkK < 0 {
+		return fmt.Errorf("fork-children muss >= 0 sein")
+	}
+	if *forkK > 0 && *mode == bench.ModeFileShared {
+		return fmt.Errorf("fork-children ist im Modus %s nicht möglich", *mode)
+	}
+
+	pageSize := os.Getpagesize()
+	var buf []byte
+	var limit atomic.Int64 // aktuell belegter Bereich von buf
+
+	switch *mode {
+	case bench.ModeFileShared:
+		// Kontrollgruppe: Sharing über den Page Cache statt über KSM.
+		if *dirtyPct > 0 || *redirtyMs > 0 || spec.Ballooning() {
+			return fmt.Errorf("modus %s ist read-only: dirty-pct, redirty und Ballooning sind nicht möglich", *mode)
+		}
+		if buf, err = mapSharedFile(*shared, pageSize); err != nil {
+			return err
+		}
+		limit.Store(int64(len(buf)))
+	case bench.ModeAnon, "":
+		size := int64(*memMiB) * 1024 * 1024
+		capacity := size
+		if spec.GrowTo != nil {
+			capacity = int64(spec.GrowTo.MiB) * 1024 * 1024
+		}
+		if capacity > math.MaxInt32 { // keep it reasonable for MVP
+			return fmt.Errorf("mem-mib ist zu groß für dieses MVP")
+		}
+
+		// Anonymes Mapping statt make(): so lässt sich der Footprint beim Ballooning
+		// per MADV_DONTNEED wieder abgeben. Nur die ersten size Bytes werden angefasst.
+		buf, err = syscall.Mmap(-1, 0, int(capacity), syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_PRIVATE|syscall.MAP_ANON)
+		if err != nil {
+			return fmt.Errorf("mmap: %w", err)
+		}
+		limit.Store(size)
+
+		// Page-Inhalt: identisch über alle Prozesse (damit KSM wirklich mergen kann)
+		if err := bench.FillPattern(buf[:size], pageSize, *pattern, uint64(*seed)); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unbekannter Modus %q", *mode)
+	}
+
+	// Welche Pages machen wir individuell?
+	totalPages := int(limit.Load()) / pageSize
+	dirtyPages := int(float64(totalPages) * (*dirtyPct / 100.0))
+	indices := make([]int, 0, dirtyPages)
+	if dirtyPages > 0 {
+		for j := 0; j < dirtyPages; j++ {
+			// deterministisch, aber pro Instanz unterschiedlich:
+			idx := int((uint64(*id)*1315423911 + uint64(j)*2654435761) % uint64(totalPages))
+			indices = append(indices, idx)
+		}
+		applyDirty(buf, pageSize, *id, indices, 0)
+	}
+
+	// madvise vor dem fork(), damit dontfork/wipeonfork für die Kinder greifen.
+	advices, err := bench.ParseMadvise(*madv)
+	if err != nil {
+		return err
+	}
+	for _, name := range advices {
+		f, _ := bench.LookupMadvise(name)
+		if err := syscall.Madvise(buf, f.Advice); err != nil {
+			return fmt.Errorf("madvise %s: %w", name, err)
+		}
+	}
+
+	// Fork-Cluster: Kinder erben alle Pages per CoW und machen danach
+	// (bei dirty-pct > 0) eigene Pages individuell, wie der Hog selbst.
+	var children []int
+	if *forkK > 0 {
+		dirty := make([][]int, *forkK)
+		for c := range dirty {
+			if slices.Contains(advices, "dontfork") {
+				continue // Kinder haben den Bereich nicht, Zugriffe wären SIGSEGV
+			}
+			cid := uint64(*id)*1000 + uint64(c) + 1
+			dirty[c] = make([]int, 0, dirtyPages)
+			for j := 0; j < dirtyPages; j++ {
+				dirty[c] = append(dirty[c], int((cid*1315423911+uint64(j)*2654435761)%uint64(totalPages)))
+			}
+		}
+		if children, err = forkChildren(*forkK, buf, pageSize, *id, dirty); err != nil {
+			return err
+		}
+		defer killChildren(children)
+	}
+
+	// Signal handling: sauber beenden.
+	sigCh := make(chan os.Signal, 2)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	var cycles atomic.Uint64
+
+	if *reportFD > 0 {
+		if *reportMs <= 0 {
+			return fmt.Errorf("report-ms muss > 0 sein")
+		}
+		w := os.NewFile(uintptr(*reportFD), "report")
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer w.Close()
+			reportLoop(done, w, *id, time.Duration(*reportMs)*time.Millisecond, &cycles, children)
+		}()
+	}
+
+	if spec.Ballooning() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			balloonLoop(done, buf, pageSize, *pattern, uint64(*seed), spec, &limit)
+		}()
+	}
+
+	// Redirty: jede Goroutine bearbeitet einen eigenen Chunk mit eigenem (gejittertem) Takt,
+	// damit ksmd keine synchronisierten Spitzen sieht.
+	if *redirtyMs > 0 && len(indices) > 0 {
+		w := *workers
+		if w > len(indices) {
+			w = len(indices)
+		}
+		perWorkerPPS := 0.0
+		if *ratePPS > 0 {
+			perWorkerPPS = float64(*ratePPS) / float64(w)
+		}
+		chunk := (len(indices) + w - 1) / w
+		for k := 0; k < w; k++ {
+			lo, hi := k*chunk, (k+1)*chunk
+			if hi > len(indices) {
+				hi = len(indices)
+			}
+			wg.Add(1)
+			go func(k int, part []int) {
+				defer wg.Done()
+				redirtyLoop(done, buf, pageSize, *id, part, redirtyConfig{
+					interval:  time.Duration(*redirtyMs) * time.Millisecond,
+					jitterPct: *jitterPct,
+					pps:       perWorkerPPS,
+					rng:       rand.New(rand.NewSource(int64(*id)<<16 | int64(k))),
+					cycles:    &cycles,
+					limit:     &limit,
+				})
+			}(k, indices[lo:hi])
+		}
+	}
+
+	// Warten, bis wir beendet werden.
+	<-sigCh
+	close(done)
+	wg.Wait()
+	return syscall.Munmap(buf)
+}
+
+// mapSharedFile mappt path read-only und MAP_SHARED und liest jede Page einmal,
+// damit sie im RSS auftaucht (geteilt über den Page Cache, nicht über KSM).
+func mapSharedFile(path string, pageSize int) ([]byte, error) {
+	if path == "" {
+		return nil, fmt.Errorf("shared-file fehlt")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	st, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if st.Size() == 0 || st.Size() > math.MaxInt32 {
+		return nil, fmt.Errorf("%s: ungültige Größe %d", path, st.Size())
+	}
+	buf, err := syscall.Mmap(int(f.Fd()), 0, int(st.Size()), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, fmt.Errorf("mmap %s: %w", path, err)
+	}
+	for off := 0; off < len(buf); off += pageSize {
+		touchSink ^= buf[off]
+	}
+	return buf, nil
+}
+
+// touchSink verhindert, dass der Compiler die Lesezugriffe in mapSharedFile wegoptimiert.
+var touchSink byte
+
+// balloonLoop führt den Grow/Shrink-Zeitplan aus (Zeiten ab Hog-Start).
+func balloonLoop(done <-chan struct{}, buf []byte, pageSize int, pattern string, seed uint64, spec bench.HogSpec, limit *atomic.Int64) {
+	start := time.Now()
+	for _, ev := range []*bench.Balloon{spec.GrowTo, spec.ShrinkTo} {
+		if ev == nil {
+			continue
+		}
+		t := time.NewTimer(time.Until(start.Add(ev.At)))
+		select {
+		case <-done:
+			t.Stop()
+			return
+		case <-t.C:
+		}
+		target := int64(ev.MiB) * 1024 * 1024
+		cur := limit.Load()
+		if target > cur {
+			// Wachsen: neue Pages mit demselben Muster befüllen (mergebar wie der Rest).
+			_ = bench.FillPattern(buf[cur:target], pageSize, pattern, seed)
+			limit.Store(target)
+		} else if target < cur {
+			// Schrumpfen: erst Redirty begrenzen, dann Pages an den Kernel zurückgeben.
+			limit.Store(target)
+			_ = syscall.Madvise(buf[target:cur], syscall.MADV_DONTNEED)
+		}
+	}
+}
+
+// reportLoop schreibt sofort und dann alle interval eine bench.HogReport-Zeile nach w.
+// Schreibfehler (Parent weg) beenden nur die Selbstauskunft, nicht den Hog.
+func reportLoop(done <-chan struct{}, w *os.File, id int, interval time.Duration, cycles *atomic.Uint64, children []int) {
+	enc := json.NewEncoder(w)
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		rep := bench.HogReport{ID: id, Time: time.Now().UTC(), DirtyCycles: cycles.Load(), ChildPIDs: children}
+		if b, err := os.ReadFile("/proc/self/statm"); err == nil {
+			if f := strings.Fields(string(b)); len(f) >= 2 {
+				pages, _ := strconv.ParseUint(f[1], 10, 64)
+				rep.RSSKB = pages * uint64(os.Getpagesize()) / 1024
+			}
+		}
+		if st, err := ksm.ReadProcessStat(0); err == nil {
+			rep.KsmStat = true
+			rep.MergingPages = st["ksm_merging_pages"]
+			rep.RmapItems = st["ksm_rmap_items"]
+			rep.Profit = st["ksm_process_profit"]
+		}
+		if err := enc.Encode(rep); err != nil {
+			return
+		}
+		select {
+		case <-done:
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// redirtyConfig steuert eine Redirty-Goroutine.
+type redirtyConfig struct {
+	interval  time.Duration
+	jitterPct float64    // 0..100
+	pps       float64    // Pages/s, 0 = unbegrenzt
+	rng       *rand.Rand // pro Goroutine, nicht nebenläufig genutzt
+	cycles    *atomic.Uint64
+	limit     *atomic.Int64 // Pages jenseits davon sind weggeballoont und werden übersprungen
+}
+
+// redirtyBatch ist die Anzahl Pages, nach der das Rate-Limit greift.
+const redirtyBatch = 64
+
+// redirtyLoop beschreibt part alle interval (± jitter) neu, bis done geschlossen wird.
+func redirtyLoop(done <-chan struct{}, buf []byte, pageSize, id int, part []int, rc redirtyConfig) {
+	var counter uint64
+	wait := func(d time.Duration) bool {
+		t := time.NewTimer(d)
+		defer t.Stop()
+		select {
+		case <-done:
+			return false
+		case <-t.C:
+			return true
+		}
+	}
+
+	// Startversatz, damit die Goroutinen nicht im Gleichschritt loslaufen.
+	if rc.jitterPct > 0 && !wait(time.Duration(rc.rng.Int63n(int64(rc.interval)+1))) {
+		return
+	}
+	for {
+		d := rc.interval
+		if rc.jitterPct > 0 {
+			f := 1 + (rc.rng.Float64()*2-1)*rc.jitterPct/100
+			d = time.Duration(float64(d) * f)
+		}
+		if !wait(d) {
+			return
+		}
+		counter++
+		for lo := 0; lo < len(part); lo += redirtyBatch {
+			hi := lo + redirtyBatch
+			if hi > len(part) {
+				hi = len(part)
+			}
+			applyDirty(buf[:rc.limit.Load()], pageSize, id, part[lo:hi], counter)
+			if rc.pps > 0 && hi < len(part) {
+				if !wait(time.Duration(float64(hi-lo) / rc.pps * float64(time.Second))) {
+					return
+				}
+			}
+		}
+		rc.cycles.Add(1)
+	}
+}
+
+func applyDirty(buf []byte, pageSize int, id int, indices []int, counter uint64) {
+	for _, idx := range indices {
+		off := idx * pageSize
+		if off+8 <= len(buf) {
+			// Write unique marker at beginning of the page
+			v := (uint64(id) << 32) ^ counter ^ 0xBADC0FFEE
+			binary.LittleEndian.PutUint64(buf[off:], v)
+		}
+	}
+}
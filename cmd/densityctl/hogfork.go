@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// forkChildren forkt k Kinder, die buf per Copy-on-Write mit dem Hog teilen
+// (wie Worker eines Prefork-Servers oder Redis-Snapshots). Kind c beschreibt
+// danach seine Pages aus dirty[c] und schläft bis zum Signal.
+//
+// Nach fork() läuft im Kind nur ein Thread ohne funktionsfähige Go-Runtime:
+// childLoop benutzt deshalb ausschließlich Raw-Syscalls und Stores ohne Allokation.
+func forkChildren(k int, buf []byte, pageSize, id int, dirty [][]int) ([]int, error) {
+	pids := make([]int, 0, k)
+	syscall.ForkLock.Lock()
+	defer syscall.ForkLock.Unlock()
+	for c := 0; c < k; c++ {
+		marker := (uint64(id) << 32) ^ uint64(c+1)<<16 ^ 0xF0F0C0DE
+		r1, _, errno := syscall.RawSyscall6(syscall.SYS_CLONE, uintptr(syscall.SIGCHLD), 0, 0, 0, 0, 0)
+		if errno != 0 {
+			killChildren(pids)
+			return nil, fmt.Errorf("fork: %w", errno)
+		}
+		if r1 == 0 {
+			childLoop(buf, pageSize, dirty[c], marker)
+		}
+		pids = append(pids, int(r1))
+	}
+	return pids, nil
+}
+
+// sigactionDfl entspricht struct kernel_sigaction mit SIG_DFL (alle Felder 0).
+type sigactionDfl struct {
+	handler, flags, restorer, mask uintptr
+}
+
+//go:nosplit
+func childLoop(buf []byte, pageSize int, dirty []int, marker uint64) {
+	// Mit dem Hog sterben und SIGTERM nicht an den (im Kind toten) Go-Handler geben.
+	syscall.RawSyscall(syscall.SYS_PRCTL, syscall.PR_SET_PDEATHSIG, uintptr(syscall.SIGKILL), 0)
+	var sa sigactionDfl
+	syscall.RawSyscall6(syscall.SYS_RT_SIGACTION, uintptr(syscall.SIGTERM), uintptr(unsafe.Pointer(&sa)), 0, 8, 0, 0)
+	syscall.RawSyscall6(syscall.SYS_RT_SIGACTION, uintptr(syscall.SIGINT), uintptr(unsafe.Pointer(&sa)), 0, 8, 0, 0)
+
+	for _, idx := range dirty {
+		off := idx * pageSize
+		if off+8 <= len(buf) {
+			binary.LittleEndian.PutUint64(buf[off:], marker)
+		}
+	}
+
+	ts := syscall.Timespec{Sec: 3600}
+	for {
+		syscall.RawSyscall(syscall.SYS_NANOSLEEP, uintptr(unsafe.Pointer(&ts)), 0, 0)
+	}
+}
+
+// killChildren beendet die Kinder (SIGTERM, nach 2s SIGKILL) und sammelt sie ein.
+func killChildren(pids []int) {
+	for _, pid := range pids {
+		_ = syscall.Kill(pid, syscall.SIGTERM)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for _, pid := range pids {
+		for {
+			var ws syscall.WaitStatus
+			wpid, err := syscall.Wait4(pid, &ws, syscall.WNOHANG, nil)
+			if wpid == pid || err != nil {
+				break
+			}
+			if time.Now().After(deadline) {
+				_ = syscall.Kill(pid, syscall.SIGKILL)
+				_, _ = syscall.Wait4(pid, &ws, 0, nil)
+				break
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+}
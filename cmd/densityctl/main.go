@@ -2,21 +2,20 @@ package main
 
 import (
 	"context"
-	"encoding/binary"
 	"encoding/json"
 	"flag"
 	"fmt"
-	"math"
 	"os"
-	"os/signal"
 	"path/filepath"
+	"runtime"
 	"sort"
-	"strconv"
 	"strings"
-	"syscall"
+	"text/tabwriter"
 	"time"
 
 	"github.com/LglzNL/density/internal/bench"
+	"github.com/LglzNL/density/internal/history"
+	"github.com/LglzNL/density/internal/jsonpath"
 	"github.com/LglzNL/density/internal/ksm"
 )
 
@@ -36,7 +35,25 @@ func main() {
 	cmd := os.Args[1]
 	args := os.Args[2:]
 
-	var err error
+	// Fault-Injection für Tests/Chaos-Experimente: DENSITY_FAULTS=... oder --faults=... vor dem Befehl.
+	faults, err := ksm.FaultsFromEnv()
+	if strings.HasPrefix(cmd, "--faults=") {
+		faults, err = ksm.ParseFaults(strings.TrimPrefix(cmd, "--faults="))
+		if len(args) == 0 {
+			usage()
+			os.Exit(2)
+		}
+		cmd, args = args[0], args[1:]
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Fehler: %v\n", err)
+		os.Exit(2)
+	}
+	if faults != nil {
+		fmt.Fprintf(os.Stderr, "WARNUNG: Fault-Injection aktiv (%s)\n", faults)
+		ksm.SetFaults(faults)
+	}
+
 	switch cmd {
 	case "help", "-h", "--help":
 		usage()
@@ -49,6 +66,10 @@ func main() {
 		err = cmdStatus(args)
 	case "bench":
 		err = cmdBench(args)
+	case "results":
+		err = cmdResults(args)
+	case "history":
+		err = cmdHistory(args)
 	case "__hog":
 		// Internes Subcommand für Benchmarks (nicht dokumentiert für Endnutzer).
 		err = cmdHog(args)
@@ -75,7 +96,9 @@ Befehle:
   enable     KSM aktivieren (konservative Defaults, optional anpassen)
   disable    KSM deaktivieren (optional: unmerge)
   status     KSM-Status/Stats anzeigen
-  bench      reproduzierbarer Benchmark (P1–P3)
+  bench      reproduzierbarer Benchmark (P1–P3 oder eigene Profile, siehe 'bench profiles')
+  results    Bench-Ergebnisse durchsuchen (list/show/filter)
+  history    Sample-Historie aufzeichnen und auswerten (record/heatmap/knobs)
 
 Hinweis:
   Dieses MVP nutzt ausschließlich standardisierte Kernel-Interfaces (sysfs).
@@ -84,7 +107,12 @@ Hinweis:
 Beispiele:
   sudo densityctl enable
   densityctl status
+  densityctl status --field pages_sharing
+  densityctl bench calibrate --duration-sec 180
+  densityctl bench profiles --profiles-file ./profiles.json
   sudo densityctl bench --profile P1 --scale 10..80 --mem-mib 256 --out results --publish docs/data/benchmarks.latest.json
+  densityctl results filter --profile P2 --since 30d --min-n 40
+  densityctl results show latest --jsonpath '{.steps[0].estimated_saved_mib}'
 
 `, projectName)
 }
@@ -97,6 +125,7 @@ func cmdEnable(args []string) error {
 		sleepMs   = fs.Int("sleep-ms", 20, "KSM: sleep_millisecs (konservativ: 20)")
 		mergeAN   = fs.Int("merge-across-nodes", -1, "KSM: merge_across_nodes (0/1). -1 = nicht ändern")
 		dryRun    = fs.Bool("dry-run", false, "Nur anzeigen, nichts schreiben")
+		knobLog   = fs.String("knob-log", history.DefaultKnobsPath, "Knob-Änderungen hier protokollieren (leer = aus)")
 	)
 	if err := fs.Parse(args); err != nil {
 		return err
@@ -115,7 +144,13 @@ func cmdEnable(args []string) error {
 		MergeAcrossNodes: *mergeAN,
 	}
 
-	if err := ksm.Enable(cfg, false); err != nil {
+	before := ksm.ReadKnobs(*ksmPath)
+	err := ksm.Enable(cfg, false)
+	if *knobLog != "" {
+		// Auch bei Teilfehlern protokollieren, was tatsächlich geschrieben wurde.
+		recordKnobChanges(*knobLog, before, ksm.ReadKnobs(*ksmPath), "density:enable")
+	}
+	if err != nil {
 		return err
 	}
 
@@ -130,6 +165,7 @@ func cmdDisable(args []string) error {
 		unmerge  = fs.Bool("unmerge", true, "run=2 (unmerge) und warten bis pages_shared=0 (best-effort)")
 		timeoutS = fs.Int("timeout-sec", 60, "Timeout in Sekunden für unmerge-wait")
 		dryRun   = fs.Bool("dry-run", false, "Nur anzeigen, nichts schreiben")
+		knobLog  = fs.String("knob-log", history.DefaultKnobsPath, "Knob-Änderungen hier protokollieren (leer = aus)")
 	)
 	if err := fs.Parse(args); err != nil {
 		return err
@@ -140,7 +176,12 @@ func cmdDisable(args []string) error {
 		return nil
 	}
 
-	if err := ksm.Disable(*ksmPath, *unmerge, time.Duration(*timeoutS)*time.Second, false); err != nil {
+	before := ksm.ReadKnobs(*ksmPath)
+	err := ksm.Disable(*ksmPath, *unmerge, time.Duration(*timeoutS)*time.Second, false)
+	if *knobLog != "" {
+		recordKnobChanges(*knobLog, before, ksm.ReadKnobs(*ksmPath), "density:disable")
+	}
+	if err != nil {
 		return err
 	}
 	fmt.Println("OK: KSM ist deaktiviert (run=0).")
@@ -152,6 +193,8 @@ func cmdStatus(args []string) error {
 	var (
 		ksmPath = fs.String("ksm-path", ksm.DefaultPath, "KSM sysfs Pfad")
 		asJSON  = fs.Bool("json", false, "Als JSON ausgeben")
+		field   = fs.String("field", "", "Nur dieses Feld ausgeben (z.B. pages_sharing)")
+		jp      = fs.String("jsonpath", "", "Ausgabe per jsonpath selektieren, z.B. '{.pages_sharing}'")
 	)
 	if err := fs.Parse(args); err != nil {
 		return err
@@ -162,6 +205,10 @@ func cmdStatus(args []string) error {
 		return err
 	}
 
+	if ok, err := printSelection(st, *field, *jp); ok || err != nil {
+		return err
+	}
+
 	if *asJSON {
 		b, _ := json.MarshalIndent(st, "", "  ")
 		fmt.Println(string(b))
@@ -182,20 +229,107 @@ func cmdStatus(args []string) error {
 }
 
 func cmdBench(args []string) error {
+	if len(args) > 0 && args[0] == "calibrate" {
+		return cmdBenchCalibrate(args[1:])
+	}
+	if len(args) > 0 && args[0] == "profiles" {
+		return cmdBenchProfiles(args[1:])
+	}
+
 	fs := flag.NewFlagSet("bench", flag.ContinueOnError)
 	var (
-		profile = fs.String("profile", "P1", "Profil: P1 (identisch), P2 (ähnlich), P3 (worst-case)")
-		scale   = fs.String("scale", "", "Skala: z.B. 10..80 oder 10..80..10")
+		profile = fs.String("profile", "P1", "Profil: P1 (identisch), P2 (ähnlich), P3 (worst-case) oder benutzerdefiniert (siehe 'bench profiles')")
+		profs   = fs.String("profiles-file", bench.DefaultProfilesPath, "Datei mit benutzerdefinierten Profilen")
+		scale   = fs.String("scale", "", "Skala: z.B. 10..80, 10..80..10, 80..10..10, 1,2,5,10..50..10 oder log:1..256")
 		n       = fs.Int("instances", 0, "Alternativ: fixe Anzahl Instanzen")
-		memMiB  = fs.Int("mem-mib", 256, "RAM pro Instanz (MiB)")
+		memMiB  = fs.String("mem-mib", "256", "RAM pro Instanz (MiB), auch als Sweep: z.B. 128..1024..128")
 		warmup  = fs.Int("warmup-sec", 20, "Warmup in Sekunden (Zeit für KSM-Merge)")
 		outDir  = fs.String("out", "results", "Output-Verzeichnis")
 		publish = fs.String("publish", "", "Optional: zusätzliches JSON an diesen Pfad schreiben (für GitHub Pages), z.B. docs/data/benchmarks.latest.json")
+		estim   = fs.String("estimator", "pages_sharing", "Schätzer für Saved: pages_sharing|general_profit|pss_delta|cgroup_delta")
+
+		precheck  = fs.Bool("precheck", true, "Vor jedem Step Umgebung prüfen (KSM-Knobs, Load, fremde Speicherverbraucher)")
+		needRun   = fs.Bool("require-ksm", false, "Precheck: KSM muss laufen (run=1)")
+		maxLoad   = fs.Float64("max-load", float64(runtime.NumCPU()), "Precheck: maximale 1-Minuten-Load (0 = aus)")
+		maxDrop   = fs.Int("max-mem-drop-mib", 512, "Precheck: max. Rückgang von MemAvailable seit Run-Beginn (0 = aus)")
+		reapply   = fs.Bool("reapply", false, "Precheck: abweichende KSM-Knobs auf Run-Beginn zurücksetzen")
+		waitQuiet = fs.Int("wait-quiet-sec", 0, "Precheck: bis zu X Sekunden warten, bis der Host ruhig ist")
+		reserve   = fs.Int("reserve-mib", bench.ReserveAuto, "Host-Reserve (MiB) für OS/Dienste; Steps, die sie unterschreiten, werden übersprungen (-1 = auto: 10% RAM, 512..4096; 0 = aus)")
+		allowSwap = fs.Bool("allow-swap", false, "Freien Swap für den Reserve-Guard mitzählen (Steps über dem RAM laufen statt übersprungen zu werden)")
+		tempSwap  = fs.Int("temp-swap-mib", 0, "Opt-in: fehlt Swap für den größten Step, temporäre Swapdatei dieser Größe anlegen und danach entfernen (root)")
+		swapPath  = fs.String("temp-swap-path", bench.DefaultTempSwapPath, "Pfad der temporären Swapdatei")
+		calPath   = fs.String("calibration", "", "Kalibrierung aus 'bench calibrate' (default: <out>/calibration.json, falls vorhanden)")
+
+		dirtyPct  = fs.Float64("dirty-pct", 0, "Überschreibt das Profil: Anteil individueller Pages pro Instanz (0..100)")
+		redirtyMs = fs.Int("redirty-ms", 0, "Überschreibt das Profil: individuelle Pages alle X ms neu beschreiben (0 = nie)")
+		pattern   = fs.String("pattern", bench.PatternConst, "Überschreibt das Profil: Inhaltsmuster const|zero|random")
+		rdWorkers = fs.Int("redirty-workers", 1, "Überschreibt das Profil: Redirty-Goroutinen pro Instanz")
+		rdJitter  = fs.Float64("redirty-jitter-pct", 0, "Überschreibt das Profil: Jitter des Redirty-Intervalls (0..100 %)")
+		rdRate    = fs.Int("redirty-rate-pps", 0, "Überschreibt das Profil: max. Redirty-Pages/s pro Instanz (0 = unbegrenzt)")
+		growTo    = fs.String("grow-to", "", "Ballooning: MIB@DAUER, jede Instanz wächst nach DAUER auf MIB (z.B. 512@10s)")
+		shrinkTo  = fs.String("shrink-to", "", "Ballooning: MIB@DAUER, jede Instanz schrumpft nach DAUER auf MIB")
+		madv      = fs.String("madvise", "", "madvise-Hinweise pro Instanz, Komma-Liste: mergeable,unmergeable,dontfork,wipeonfork,hugepage,nohugepage")
+		forkK     = fs.Int("fork-children", 0, "Fork-Cluster: jede Instanz forkt K Kinder nach der Allokation")
+		mode      = fs.String("mode", bench.ModeAnon, "Speichermodus: anon (KSM) | file-shared (Kontrollgruppe: gemeinsame Datei, MAP_SHARED)")
 	)
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
 
+	reg, err := bench.LoadProfiles(*profs, !flagSet(fs, "profiles-file"))
+	if err != nil {
+		return err
+	}
+	def, err := reg.Lookup(*profile)
+	if err != nil {
+		return err
+	}
+	prof := bench.Profile(def.Name)
+	var hog *bench.HogSpec
+	if !def.Builtin {
+		spec := def.Spec()
+		hog = &spec
+	}
+	var flagErr error
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "dirty-pct", "redirty-ms", "pattern", "redirty-workers", "redirty-jitter-pct", "redirty-rate-pps", "grow-to", "shrink-to", "mode", "fork-children", "madvise":
+		default:
+			return
+		}
+		if hog == nil {
+			spec := def.Spec()
+			hog = &spec
+		}
+		switch f.Name {
+		case "dirty-pct":
+			hog.DirtyPct = *dirtyPct
+		case "redirty-ms":
+			hog.Redirty = time.Duration(*redirtyMs) * time.Millisecond
+		case "pattern":
+			hog.Pattern = *pattern
+		case "redirty-workers":
+			hog.Workers = *rdWorkers
+		case "redirty-jitter-pct":
+			hog.JitterPct = *rdJitter
+		case "redirty-rate-pps":
+			hog.RatePPS = *rdRate
+		case "mode":
+			hog.Mode = *mode
+		case "fork-children":
+			hog.ForkChildren = *forkK
+		case "madvise":
+			hog.Madvise, flagErr = bench.ParseMadvise(*madv)
+		case "grow-to":
+			hog.GrowTo, flagErr = bench.ParseBalloon(*growTo)
+		case "shrink-to":
+			hog.ShrinkTo, flagErr = bench.ParseBalloon(*shrinkTo)
+		}
+	})
+	if flagErr != nil {
+		return flagErr
+	}
+
 	exe, err := os.Executable()
 	if err != nil {
 		return err
@@ -203,7 +337,7 @@ func cmdBench(args []string) error {
 
 	var instances []int
 	if *scale != "" {
-		instances, err = parseScale(*scale)
+		instances, err = bench.ParseScale(*scale)
 		if err != nil {
 			return err
 		}
@@ -213,15 +347,44 @@ func cmdBench(args []string) error {
 		return fmt.Errorf("bitte --scale oder --instances angeben")
 	}
 
+	est, err := bench.ParseEstimator(*estim)
+	if err != nil {
+		return err
+	}
+
+	mems, err := bench.ParseScale(*memMiB)
+	if err != nil {
+		return fmt.Errorf("--mem-mib: %w", err)
+	}
+
+	cal, err := loadCalibration(*calPath, *outDir)
+	if err != nil {
+		return err
+	}
+
 	ctx := context.Background()
 	cfg := bench.Config{
 		ExecPath:  exe,
 		OutDir:    *outDir,
 		KSMPath:   ksm.DefaultPath,
-		Profile:   bench.Profile(strings.ToUpper(*profile)),
+		Profile:   prof,
 		Instances: instances,
-		MemMiB:    *memMiB,
+		MemMiB:    mems[0],
+		MemSizes:  mems,
 		Warmup:    time.Duration(*warmup) * time.Second,
+		Estimator: est,
+		Precheck: bench.PrecheckConfig{
+			Enabled:       *precheck,
+			RequireKSMRun: *needRun,
+			MaxLoad1:      *maxLoad,
+			MaxMemDropMiB: *maxDrop,
+			Reapply:       *reapply,
+			WaitQuiet:     time.Duration(*waitQuiet) * time.Second,
+		},
+		ReserveMiB:  *reserve,
+		Swap:        bench.SwapConfig{Allow: *allowSwap, TempMiB: *tempSwap, TempPath: *swapPath},
+		Calibration: cal,
+		Hog:         hog,
 	}
 
 	res, err := bench.Run(ctx, cfg)
@@ -230,6 +393,9 @@ func cmdBench(args []string) error {
 	}
 
 	fmt.Printf("OK: Benchmark fertig. Report: %s\n", filepath.Join(*outDir, "report.md"))
+	if res.Swap != nil && res.Swap.Warning != "" {
+		fmt.Printf("WARNUNG: Swap: %s\n", res.Swap.Warning)
+	}
 
 	if *publish != "" {
 		b, _ := json.MarshalIndent(res, "", "  ")
@@ -244,126 +410,78 @@ func cmdBench(args []string) error {
 	return nil
 }
 
-// parseScale parses "min..max" or "min..max..step".
-func parseScale(s string) ([]int, error) {
-	parts := strings.Split(s, "..")
-	if len(parts) != 2 && len(parts) != 3 {
-		return nil, fmt.Errorf("ungültiges scale-Format: %q (erwartet min..max oder min..max..step)", s)
-	}
-	min, err := strconv.Atoi(strings.TrimSpace(parts[0]))
-	if err != nil {
-		return nil, err
-	}
-	max, err := strconv.Atoi(strings.TrimSpace(parts[1]))
-	if err != nil {
-		return nil, err
-	}
-	step := 1
-	if len(parts) == 3 {
-		step, err = strconv.Atoi(strings.TrimSpace(parts[2]))
-		if err != nil {
-			return nil, err
-		}
-		if step <= 0 {
-			return nil, fmt.Errorf("step muss > 0 sein")
-		}
-	}
-	if min <= 0 || max <= 0 || max < min {
-		return nil, fmt.Errorf("ungültige Grenzen: %d..%d", min, max)
-	}
-
-	var out []int
-	for i := min; i <= max; i += step {
-		out = append(out, i)
-	}
-	return out, nil
-}
-
-// cmdHog ist ein kontrollierter RAM-Allocator für Benchmarks.
-// Er erzeugt (auf Wunsch) identische Pages zwischen Prozessen (gut für P1/P2) und kann Pages gezielt \"verschmutzen\" (P2/P3).
-func cmdHog(args []string) error {
-	fs := flag.NewFlagSet("__hog", flag.ContinueOnError)
+// cmdBenchProfiles listet eingebaute und benutzerdefinierte Profile.
+func cmdBenchProfiles(args []string) error {
+	fs := flag.NewFlagSet("bench profiles", flag.ContinueOnError)
 	var (
-		memMiB    = fs.Int("mem-mib", 256, "Allokation (MiB)")
-		id        = fs.Int("id", 0, "Instanz-ID")
-		dirtyPct  = fs.Float64("dirty-pct", 0, "Prozent der Pages, die pro Instanz individuell gemacht werden (0..100)")
-		redirtyMs = fs.Int("redirty-ms", 0, "Wenn >0: alle X ms werden die individuellen Pages erneut beschrieben (verhindert Merge)")
+		profs  = fs.String("profiles-file", bench.DefaultProfilesPath, "Datei mit benutzerdefinierten Profilen")
+		asJSON = fs.Bool("json", false, "Als JSON ausgeben")
 	)
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
-	if *memMiB <= 0 {
-		return fmt.Errorf("mem-mib muss > 0 sein")
-	}
-	if *dirtyPct < 0 || *dirtyPct > 100 {
-		return fmt.Errorf("dirty-pct muss 0..100 sein")
-	}
-
-	size := int64(*memMiB) * 1024 * 1024
-	if size > math.MaxInt32 { // keep it reasonable for MVP
-		return fmt.Errorf("mem-mib ist zu groß für dieses MVP")
-	}
-
-	pageSize := os.Getpagesize()
-	buf := make([]byte, int(size))
 
-	// Page-Template: identisch über alle Prozesse (damit KSM wirklich mergen kann)
-	template := make([]byte, pageSize)
-	for i := 0; i < len(template); i += 8 {
-		binary.LittleEndian.PutUint64(template[i:], 0x44454E5331545930) // "DENS1TY0" als konstant
+	reg, err := bench.LoadProfiles(*profs, !flagSet(fs, "profiles-file"))
+	if err != nil {
+		return err
 	}
-	for off := 0; off+pageSize <= len(buf); off += pageSize {
-		copy(buf[off:off+pageSize], template)
+	list := reg.List()
+	if *asJSON {
+		b, _ := json.MarshalIndent(list, "", "  ")
+		fmt.Println(string(b))
+		return nil
 	}
 
-	// Welche Pages machen wir individuell?
-	totalPages := len(buf) / pageSize
-	dirtyPages := int(float64(totalPages) * (*dirtyPct / 100.0))
-	indices := make([]int, 0, dirtyPages)
-	if dirtyPages > 0 {
-		for j := 0; j < dirtyPages; j++ {
-			// deterministisch, aber pro Instanz unterschiedlich:
-			idx := int((uint64(*id)*1315423911 + uint64(j)*2654435761) % uint64(totalPages))
-			indices = append(indices, idx)
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tDIRTY-%\tREDIRTY\tMUSTER\tDUP-RATIO\tQUELLE\tBESCHREIBUNG")
+	for _, d := range list {
+		src := *profs
+		if d.Builtin {
+			src = "eingebaut"
 		}
-		applyDirty(buf, pageSize, *id, indices, 0)
+		dup := "1.00"
+		if d.DupRatio > 0 {
+			dup = fmt.Sprintf("%.2f", d.DupRatio)
+		}
+		fmt.Fprintf(tw, "%s\t%.1f\t%s\t%s\t%s\t%s\t%s\n",
+			d.Name, d.DirtyPct, time.Duration(d.RedirtyMs)*time.Millisecond, d.Pattern, dup, src, d.Description)
 	}
+	return tw.Flush()
+}
 
-	// Signal handling: sauber beenden.
-	sigCh := make(chan os.Signal, 2)
-	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+// flagSet meldet, ob ein Flag explizit gesetzt wurde.
+func flagSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
 
-	var ticker *time.Ticker
-	if *redirtyMs > 0 && len(indices) > 0 {
-		ticker = time.NewTicker(time.Duration(*redirtyMs) * time.Millisecond)
-		defer ticker.Stop()
+// printSelection gibt bei gesetztem --field/--jsonpath nur die selektierten Werte aus.
+// ok=false bedeutet: keine Selektion angefordert, Aufrufer rendert normal.
+func printSelection(v any, field, expr string) (bool, error) {
+	if field == "" && expr == "" {
+		return false, nil
 	}
-
-	var counter uint64
-	if ticker == nil {
-		// Kein redirty: einfach warten, bis wir beendet werden.
-		<-sigCh
-		return nil
+	if field != "" && expr != "" {
+		return true, fmt.Errorf("--field und --jsonpath schließen sich aus")
 	}
 
-	for {
-		select {
-		case <-sigCh:
-			return nil
-		case <-ticker.C:
-			counter++
-			applyDirty(buf, pageSize, *id, indices, counter)
-		}
+	var (
+		vals []any
+		err  error
+	)
+	if field != "" {
+		vals, err = jsonpath.Field(v, field)
+	} else {
+		vals, err = jsonpath.Eval(v, expr)
 	}
-}
-
-func applyDirty(buf []byte, pageSize int, id int, indices []int, counter uint64) {
-	for _, idx := range indices {
-		off := idx * pageSize
-		if off+8 <= len(buf) {
-			// Write unique marker at beginning of the page
-			v := (uint64(id) << 32) ^ counter ^ 0xBADC0FFEE
-			binary.LittleEndian.PutUint64(buf[off:], v)
-		}
+	if err != nil {
+		return true, err
 	}
+	fmt.Println(jsonpath.Format(vals))
+	return true, nil
 }
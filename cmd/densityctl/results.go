@@ -0,0 +1,388 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/LglzNL/density/internal/bench"
+	"github.com/LglzNL/density/internal/importer"
+	"github.com/LglzNL/density/internal/results"
+	"github.com/LglzNL/density/internal/stats"
+)
+
+// cmdResults: Abfragen über das Results-Verzeichnis (list/show/filter).
+func cmdResults(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("bitte Unterbefehl angeben: results list|show|filter|import|compare|aggregate")
+	}
+	switch args[0] {
+	case "list", "filter":
+		return cmdResultsList(args[0], args[1:])
+	case "show":
+		return cmdResultsShow(args[1:])
+	case "import":
+		return cmdResultsImport(args[1:])
+	case "compare":
+		return cmdResultsCompare(args[1:])
+	case "aggregate":
+		return cmdResultsAggregate(args[1:])
+	default:
+		return fmt.Errorf("unbekannter results-Unterbefehl: %s", args[0])
+	}
+}
+
+// resultFilterFlags sind die gemeinsamen Auswahl-Flags von list/filter/aggregate.
+type resultFilterFlags struct {
+	dir     *string
+	profile *string
+	since   *string
+	minN    *int
+	maxN    *int
+}
+
+func addResultFilterFlags(fs *flag.FlagSet) *resultFilterFlags {
+	return &resultFilterFlags{
+		dir:     fs.String("dir", "results", "Results-Verzeichnis (rekursiv durchsucht)"),
+		profile: fs.String("profile", "", "Nur dieses Profil (z.B. P2)"),
+		since:   fs.String("since", "", "Nur Runs jünger als z.B. 30d, 2w, 12h"),
+		minN:    fs.Int("min-n", 0, "Nur Runs mit mindestens einem Step N >= min-n"),
+		maxN:    fs.Int("max-n", 0, "Nur Runs ohne Step N > max-n"),
+	}
+}
+
+func (f *resultFilterFlags) load() ([]results.Entry, error) {
+	age, err := results.ParseAge(*f.since)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := results.Scan(*f.dir)
+	if err != nil {
+		return nil, err
+	}
+	return results.Apply(entries, results.Filter{
+		Profile: bench.Profile(strings.ToUpper(*f.profile)),
+		Since:   age,
+		MinN:    *f.minN,
+		MaxN:    *f.maxN,
+	}, time.Now()), nil
+}
+
+func cmdResultsList(name string, args []string) error {
+	fs := flag.NewFlagSet("results "+name, flag.ContinueOnError)
+	filter := addResultFilterFlags(fs)
+	var (
+		sortKey = fs.String("sort", "started", "Sortierung: started|saved|n|path")
+		desc    = fs.Bool("desc", false, "Absteigend sortieren")
+		limit   = fs.Int("limit", 0, "Maximal so viele Einträge ausgeben (0 = alle)")
+		asJSON  = fs.Bool("json", false, "Als JSON ausgeben")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	entries, err := filter.load()
+	if err != nil {
+		return err
+	}
+	if err := results.Sort(entries, *sortKey, *desc); err != nil {
+		return err
+	}
+	if *limit > 0 && len(entries) > *limit {
+		entries = entries[:*limit]
+	}
+
+	if *asJSON {
+		type row struct {
+			Path      string        `json:"path"`
+			StartedAt time.Time     `json:"started_at"`
+			Profile   bench.Profile `json:"profile"`
+			Steps     int           `json:"steps"`
+			MaxN      int           `json:"max_n"`
+			MaxSaved  float64       `json:"max_saved_mib"`
+		}
+		rows := make([]row, 0, len(entries))
+		for _, e := range entries {
+			rows = append(rows, row{e.Path, e.Result.StartedAt, e.Result.Profile, len(e.Result.Steps), e.MaxN, e.MaxSaved})
+		}
+		b, _ := json.MarshalIndent(rows, "", "  ")
+		fmt.Println(string(b))
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "STARTED\tPROFIL\tSTEPS\tMAX-N\tMAX-SAVED-MIB\tPFAD")
+	for _, e := range entries {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%.1f\t%s\n",
+			e.Result.StartedAt.Format(time.RFC3339), e.Result.Profile, len(e.Result.Steps), e.MaxN, e.MaxSaved, e.Path)
+	}
+	return tw.Flush()
+}
+
+func cmdResultsShow(args []string) error {
+	fs := flag.NewFlagSet("results show", flag.ContinueOnError)
+	var (
+		dir    = fs.String("dir", "results", "Results-Verzeichnis (für latest/#index)")
+		asJSON = fs.Bool("json", false, "Rohes JSON ausgeben")
+		field  = fs.String("field", "", "Nur dieses Top-Level-Feld ausgeben (z.B. profile)")
+		jp     = fs.String("jsonpath", "", "Ausgabe per jsonpath selektieren, z.B. '{.steps[0].estimated_saved_mib}'")
+	)
+	pos, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		return fmt.Errorf("bitte genau ein Ergebnis angeben: <datei> | latest | #<index>")
+	}
+
+	e, err := resolveResult(*dir, pos[0])
+	if err != nil {
+		return err
+	}
+
+	if ok, err := printSelection(e.Result, *field, *jp); ok || err != nil {
+		return err
+	}
+	if *asJSON {
+		b, _ := json.MarshalIndent(e.Result, "", "  ")
+		fmt.Println(string(b))
+		return nil
+	}
+
+	r := e.Result
+	fmt.Printf("%s\n  Zeitpunkt: %s\n  Profil:    %s\n\n", e.Path, r.StartedAt.Format(time.RFC3339), r.Profile)
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "N\tALIVE\tMEM-MIB\tSAVED-MIB\tKSMD-TICKS\tNOTES")
+	for _, s := range r.Steps {
+		fmt.Fprintf(tw, "%d\t%d\t%d\t%.1f\t%d\t%s\n", s.N, s.Alive, s.MemMiB, s.EstimatedSavedMiB, s.KsmdTicksDelta, s.Notes)
+	}
+	return tw.Flush()
+}
+
+// cmdResultsImport konvertiert Fremddaten in ein bench_*.json im Results-Verzeichnis,
+// damit list/show/Reports sie wie eigene Runs behandeln.
+func cmdResultsImport(args []string) error {
+	fs := flag.NewFlagSet("results import", flag.ContinueOnError)
+	var (
+		format  = fs.String("format", "", "Quellformat: "+strings.Join(importer.Formats, "|"))
+		profile = fs.String("profile", "P1", "Profil, unter dem die Daten abgelegt werden")
+		outDir  = fs.String("out", "results", "Output-Verzeichnis")
+	)
+	pos, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		return fmt.Errorf("bitte genau eine Quelldatei angeben")
+	}
+	if *format == "" {
+		return fmt.Errorf("bitte --format angeben (%s)", strings.Join(importer.Formats, "|"))
+	}
+
+	f, err := os.Open(pos[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	prof := bench.Profile(strings.ToUpper(*profile))
+	res, err := importer.Import(*format, f, prof)
+	if err != nil {
+		return err
+	}
+	if res.StartedAt.IsZero() {
+		// Logs ohne Zeitstempel: mtime der Quelle ist die beste Näherung.
+		if st, err := f.Stat(); err == nil {
+			res.StartedAt = st.ModTime().UTC()
+		}
+	}
+
+	if err := os.MkdirAll(*outDir, 0o755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("bench_%s_import_%s_%s.json", strings.ToLower(string(prof)), *format, res.StartedAt.Format("20060102_150405"))
+	path := filepath.Join(*outDir, name)
+	b, _ := json.MarshalIndent(res, "", "  ")
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("OK: %d Steps importiert (%s): %s\n", len(res.Steps), *format, path)
+	return nil
+}
+
+// cmdResultsCompare vergleicht zwei Runs, ggf. normalisiert auf Host- und Instanzgröße.
+func cmdResultsCompare(args []string) error {
+	fs := flag.NewFlagSet("results compare", flag.ContinueOnError)
+	var (
+		dir   = fs.String("dir", "results", "Results-Verzeichnis (für latest/#index)")
+		norm  = fs.String("norm", "auto", "Normalisierung: auto oder Liste aus absolute,per-instance,per-gib,per-core,pct-host")
+		force = fs.Bool("force", false, "Auch nicht vergleichbare Runs gegenüberstellen (Exit-Code 0)")
+	)
+	pos, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 2 {
+		return fmt.Errorf("bitte genau zwei Ergebnisse angeben: compare <A> <B>")
+	}
+
+	var norms []results.Normalization
+	if *norm != "auto" {
+		for _, p := range strings.Split(*norm, ",") {
+			n, err := results.ParseNormalization(strings.TrimSpace(p))
+			if err != nil {
+				return err
+			}
+			norms = append(norms, n)
+		}
+	}
+
+	a, err := resolveResult(*dir, pos[0])
+	if err != nil {
+		return err
+	}
+	b, err := resolveResult(*dir, pos[1])
+	if err != nil {
+		return err
+	}
+
+	c := results.Compare(a, b, norms)
+	fmt.Printf("A: %s\nB: %s\n\n", a.Path, b.Path)
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprint(tw, "N")
+	for _, n := range c.Normalizations {
+		fmt.Fprintf(tw, "\t%s A\t%s B\tΔ%%", n, n)
+	}
+	fmt.Fprintln(tw)
+	for _, r := range c.Rows {
+		fmt.Fprintf(tw, "%d", r.N)
+		for _, n := range c.Normalizations {
+			fmt.Fprintf(tw, "\t%s\t%s\t%s", fmtNorm(r.A[n]), fmtNorm(r.B[n]), fmtDeltaPct(r.A[n], r.B[n]))
+		}
+		fmt.Fprintln(tw)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	for _, w := range c.Warnings {
+		fmt.Printf("WARNUNG: %s\n", w)
+	}
+	if !c.Comparable && !*force {
+		return fmt.Errorf("Runs sind nicht vergleichbar (--force zum Ignorieren)")
+	}
+	return nil
+}
+
+func fmtNorm(v float64) string {
+	if math.IsNaN(v) {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f", v)
+}
+
+func fmtDeltaPct(a, b float64) string {
+	if a == 0 || math.IsNaN(a) || math.IsNaN(b) {
+		return "-"
+	}
+	return fmt.Sprintf("%+.1f", (b-a)/a*100.0)
+}
+
+// cmdResultsAggregate fasst wiederholte Runs je N zusammen (Ausreißer verworfen,
+// Konfidenz annotiert, instabile Steps markiert statt einen irreführenden Mittelwert zu zeigen).
+func cmdResultsAggregate(args []string) error {
+	fs := flag.NewFlagSet("results aggregate", flag.ContinueOnError)
+	filter := addResultFilterFlags(fs)
+	var (
+		z      = fs.Float64("outlier-z", stats.DefaultOutlierZ, "Schwelle modifizierter z-Score für Ausreißer")
+		maxCV  = fs.Float64("max-cv", stats.DefaultMaxCV, "Ab diesem Variationskoeffizienten gilt ein Step als instabil")
+		asJSON = fs.Bool("json", false, "Als JSON ausgeben")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	entries, err := filter.load()
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("keine passenden Ergebnisse gefunden")
+	}
+	profiles := map[bench.Profile]bool{}
+	for _, e := range entries {
+		profiles[e.Result.Profile] = true
+	}
+	if len(profiles) > 1 {
+		return fmt.Errorf("Runs mit unterschiedlichen Profilen gefunden, bitte --profile angeben")
+	}
+
+	aggs := results.Aggregate(entries, results.AggregateOptions{OutlierZ: *z, MaxCV: *maxCV})
+	if *asJSON {
+		b, _ := json.MarshalIndent(aggs, "", "  ")
+		fmt.Println(string(b))
+		return nil
+	}
+
+	fmt.Printf("%d Runs aggregiert\n\n", len(entries))
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "N\tSAMPLES\tVERWORFEN\tSAVED-MIB (MEAN ± CI95)\tSTDDEV\tMIN\tMAX\tKONFIDENZ")
+	for _, a := range aggs {
+		mean := fmt.Sprintf("%.1f ± %.1f", a.Summary.Mean, a.Summary.CI95)
+		if a.Unstable {
+			mean = "instabil"
+		}
+		fmt.Fprintf(tw, "%d\t%d\t%d\t%s\t%.1f\t%.1f\t%.1f\t%s\n",
+			a.N, a.Samples, len(a.Rejected), mean, a.Summary.Stddev, a.Summary.Min, a.Summary.Max, a.Confidence)
+	}
+	return tw.Flush()
+}
+
+// resolveResult akzeptiert einen Dateipfad, "latest" oder "#<index>" (Index wie in "results list").
+func resolveResult(dir, ref string) (results.Entry, error) {
+	if ref != "latest" && !strings.HasPrefix(ref, "#") {
+		return results.Load(ref)
+	}
+
+	entries, err := results.Scan(dir)
+	if err != nil {
+		return results.Entry{}, err
+	}
+	if len(entries) == 0 {
+		return results.Entry{}, fmt.Errorf("keine Ergebnisse in %s gefunden", dir)
+	}
+	_ = results.Sort(entries, "started", false)
+
+	if ref == "latest" {
+		return entries[len(entries)-1], nil
+	}
+	idx, err := strconv.Atoi(ref[1:])
+	if err != nil || idx < 0 || idx >= len(entries) {
+		return results.Entry{}, fmt.Errorf("ungültiger Index %q (0..%d)", ref, len(entries)-1)
+	}
+	return entries[idx], nil
+}
+
+// parseInterspersed erlaubt Flags auch nach Positionsargumenten ("show latest --json"),
+// was flag.FlagSet alleine nicht kann. Gibt die Positionsargumente zurück.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var pos []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return pos, nil
+		}
+		pos = append(pos, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
@@ -0,0 +1,97 @@
+package bench
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Balloon ist ein Zeitplan-Eintrag: nach At (ab Hog-Start) auf MiB wachsen bzw. schrumpfen.
+type Balloon struct {
+	MiB int           `json:"mib"`
+	At  time.Duration `json:"at"`
+}
+
+// ParseBalloon parst "MIB@DAUER", z.B. "512@10s".
+func ParseBalloon(s string) (*Balloon, error) {
+	mib, at, ok := strings.Cut(strings.TrimSpace(s), "@")
+	if !ok {
+		return nil, fmt.Errorf("ungültiger Zeitplan %q (erwartet MIB@DAUER, z.B. 512@10s)", s)
+	}
+	n, err := strconv.Atoi(mib)
+	if err != nil || n <= 0 {
+		return nil, fmt.Errorf("ungültiger Zeitplan %q: MiB muss > 0 sein", s)
+	}
+	d, err := time.ParseDuration(at)
+	if err != nil || d < 0 {
+		return nil, fmt.Errorf("ungültiger Zeitplan %q: Dauer ungültig", s)
+	}
+	return &Balloon{MiB: n, At: d}, nil
+}
+
+func (b Balloon) String() string {
+	return fmt.Sprintf("%d@%s", b.MiB, b.At)
+}
+
+// Ballooning meldet, ob sich der Footprint während des Steps ändert.
+func (h HogSpec) Ballooning() bool {
+	return h.GrowTo != nil || h.ShrinkTo != nil
+}
+
+// ValidateBalloon prüft den Zeitplan gegen Instanzgröße und Warmup.
+func (h HogSpec) ValidateBalloon(memMiB int, warmup time.Duration) error {
+	peak := memMiB
+	if g := h.GrowTo; g != nil {
+		if g.MiB <= memMiB {
+			return fmt.Errorf("grow-to (%d MiB) muss größer als mem-mib (%d) sein", g.MiB, memMiB)
+		}
+		if g.At >= warmup {
+			return fmt.Errorf("grow-to bei %s liegt nicht im Warmup (%s)", g.At, warmup)
+		}
+		peak = g.MiB
+	}
+	if s := h.ShrinkTo; s != nil {
+		if s.MiB >= peak {
+			return fmt.Errorf("shrink-to (%d MiB) muss kleiner als %d MiB sein", s.MiB, peak)
+		}
+		if s.At >= warmup {
+			return fmt.Errorf("shrink-to bei %s liegt nicht im Warmup (%s)", s.At, warmup)
+		}
+		if g := h.GrowTo; g != nil && s.At <= g.At {
+			return fmt.Errorf("shrink-to (%s) muss nach grow-to (%s) liegen", s.At, g.At)
+		}
+	}
+	return nil
+}
+
+// renderTimeline rendert die Warmup-Timeline der Steps mit Ballooning.
+func renderTimeline(r *RunResult) string {
+	w := r.Workload
+	if w == nil || !w.Ballooning() {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("## Ballooning\n\n")
+	if w.GrowTo != nil {
+		b.WriteString(fmt.Sprintf("- Wachsen auf %d MiB nach %s\n", w.GrowTo.MiB, w.GrowTo.At))
+	}
+	if w.ShrinkTo != nil {
+		b.WriteString(fmt.Sprintf("- Schrumpfen auf %d MiB nach %s\n", w.ShrinkTo.MiB, w.ShrinkTo.At))
+	}
+	b.WriteString("\n")
+	for _, s := range r.Steps {
+		if len(s.Timeline) == 0 {
+			continue
+		}
+		b.WriteString(fmt.Sprintf("### N=%d, %d MiB\n\n", s.N, s.MemMiB))
+		b.WriteString("| t (s) | pages_sharing | pages_shared | RSS Hogs (MiB) |\n")
+		b.WriteString("|---:|---:|---:|---:|\n")
+		for _, t := range s.Timeline {
+			b.WriteString(fmt.Sprintf("| %.0f | %d | %d | %.1f |\n",
+				t.Offset.Seconds(), t.PagesSharing, t.PagesShared, float64(t.RSSKB)/1024))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -6,14 +6,17 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
+	"github.com/LglzNL/density/internal/clock"
 	"github.com/LglzNL/density/internal/ksm"
 )
 
@@ -35,17 +38,41 @@ type Config struct {
 	Instances []int
 
 	MemMiB   int
+	MemSizes []int // optional: Sweep über Instanzgrößen (MiB), gekreuzt mit Instances
 	Warmup   time.Duration
 	Interval time.Duration // redirty interval for P3; optional
+
+	// Hog überschreibt das Verhalten des Profils (nil = Profil-Defaults, siehe ProfileSpec).
+	Hog *HogSpec
+
+	// Estimator bestimmt EstimatedSavedMiB; alle verfügbaren Schätzer landen zusätzlich in Estimates.
+	Estimator Estimator
+
+	Precheck PrecheckConfig
+
+	// ReserveMiB bleibt für OS und Dienste frei: Steps, die mehr bräuchten, werden
+	// übersprungen. 0 = keine Reserve, ReserveAuto = DefaultReserveMiB.
+	ReserveMiB int
+
+	// Swap: Swap-Prüfung und optionaler temporärer Swap für Steps über dem RAM.
+	Swap SwapConfig
+
+	// Calibration (optional, aus "bench calibrate") dimensioniert Warmup,
+	// Precheck-Schwelle und die Rauschuntergrenze im Report.
+	Calibration *Calibration
+
+	// Clock ist die Zeitquelle für Warmup/Wartezeiten (nil = clock.Real).
+	// Tests setzen eine clock.Fake, um lange Runs ohne echtes Warten zu simulieren.
+	Clock clock.Clock
 }
 
 type StepResult struct {
-	N          int           `json:"n"`
-	Alive      int           `json:"alive"`
-	Duration   time.Duration `json:"duration"`
-	Profile    Profile       `json:"profile"`
-	MemMiB     int           `json:"mem_mib"`
-	Warmup     time.Duration `json:"warmup"`
+	N        int           `json:"n"`
+	Alive    int           `json:"alive"`
+	Duration time.Duration `json:"duration"`
+	Profile  Profile       `json:"profile"`
+	MemMiB   int           `json:"mem_mib"`
+	Warmup   time.Duration `json:"warmup"`
 
 	PreMemKB  map[string]uint64 `json:"pre_mem_kb,omitempty"`
 	PostMemKB map[string]uint64 `json:"post_mem_kb,omitempty"`
@@ -53,16 +80,95 @@ type StepResult struct {
 	PreKSM  map[string]int64 `json:"pre_ksm,omitempty"`
 	PostKSM map[string]int64 `json:"post_ksm,omitempty"`
 
-	EstimatedSavedMiB float64 `json:"estimated_saved_mib"`
-	KsmdTicksDelta    int64   `json:"ksmd_ticks_delta,omitempty"`
+	EstimatedSavedMiB float64            `json:"estimated_saved_mib"`
+	Estimator         Estimator          `json:"estimator,omitempty"`
+	Estimates         map[string]float64 `json:"estimates,omitempty"`
+	KsmdTicksDelta    int64              `json:"ksmd_ticks_delta,omitempty"`
 
 	Notes string `json:"notes,omitempty"`
+
+	// SwapUsedMiB ist die Swap-Belegung am Messzeitpunkt.
+	SwapUsedMiB float64 `json:"swap_used_mib,omitempty"`
+
+	// Skipped: Step nicht ausgeführt (z.B. Host-Reserve), Grund in Notes.
+	Skipped bool `json:"skipped,omitempty"`
+
+	// Timeline verfolgt Sharing und Footprint während des Warmups (nur bei Ballooning).
+	Timeline []TimelineSample `json:"timeline,omitempty"`
+
+	// Sharing teilt bei Fork-Clustern das Sharing in geerbtes CoW und KSM auf.
+	Sharing *SharingBreakdown `json:"sharing,omitempty"`
+
+	// Instances ist die letzte Selbstauskunft jeder Hog-Instanz zum Messzeitpunkt.
+	Instances []HogReport `json:"instances,omitempty"`
+
+	PrecheckViolations []string `json:"precheck_violations,omitempty"`
 }
 
 type RunResult struct {
 	StartedAt time.Time    `json:"started_at"`
 	Profile   Profile      `json:"profile"`
 	Steps     []StepResult `json:"steps"`
+
+	Host *HostInfo `json:"host,omitempty"`
+
+	// Workload ist das tatsächlich verwendete Hog-Verhalten (Profil-Default oder Override).
+	Workload *HogSpec `json:"workload,omitempty"`
+
+	// ReserveMiB ist die Host-Reserve, mit der der Run lief.
+	ReserveMiB int `json:"reserve_mib,omitempty"`
+
+	// Swap dokumentiert Swap-Zustand, temporären Swap und Spitzenbelegung.
+	Swap *SwapInfo `json:"swap,omitempty"`
+
+	// NoiseFloorMiB stammt aus der Kalibrierung; Ersparnisse darunter sind nicht signifikant.
+	NoiseFloorMiB float64 `json:"noise_floor_mib,omitempty"`
+
+	// Source ist leer für echte DENSITY-Runs, sonst die Herkunft importierter Daten
+	// (z.B. "stress-ng", "ksmtuned", "sysfs-csv").
+	Source string `json:"source,omitempty"`
+}
+
+// TimelineSample ist ein Messpunkt während des Warmups (Offset ab Hog-Start).
+type TimelineSample struct {
+	Offset       time.Duration `json:"offset"`
+	PagesShared  int64         `json:"pages_shared"`
+	PagesSharing int64         `json:"pages_sharing"`
+	RSSKB        uint64        `json:"rss_kb"` // Summe der Hog-Selbstauskünfte
+}
+
+// HostInfo beschreibt die Größe des Hosts, damit Runs verschiedener Maschinen
+// normalisiert verglichen werden können.
+type HostInfo struct {
+	Hostname   string `json:"hostname,omitempty"`
+	Kernel     string `json:"kernel,omitempty"`
+	CPUs       int    `json:"cpus"`
+	MemTotalKB uint64 `json:"mem_total_kb"`
+	PageSize   int    `json:"page_size"`
+}
+
+// ReadHostInfo sammelt HostInfo best-effort (fehlende Felder bleiben leer).
+func ReadHostInfo() *HostInfo {
+	h := &HostInfo{
+		CPUs:     runtime.NumCPU(),
+		PageSize: os.Getpagesize(),
+	}
+	h.Hostname, _ = os.Hostname()
+	if b, err := os.ReadFile("/proc/sys/kernel/osrelease"); err == nil {
+		h.Kernel = strings.TrimSpace(string(b))
+	}
+	if mi, err := ksm.ReadMemInfo(); err == nil {
+		h.MemTotalKB = mi["MemTotal"]
+	}
+	return h
+}
+
+// workload liefert das effektive Hog-Verhalten.
+func (c Config) workload() HogSpec {
+	if c.Hog != nil {
+		return *c.Hog
+	}
+	return ProfileSpec(c.Profile, c.Interval)
 }
 
 func Run(ctx context.Context, cfg Config) (*RunResult, error) {
@@ -84,114 +190,235 @@ func Run(ctx context.Context, cfg Config) (*RunResult, error) {
 	if cfg.Profile == "" {
 		cfg.Profile = ProfileP1
 	}
+	if cfg.Estimator == "" {
+		cfg.Estimator = EstimatorPagesSharing
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = clock.Real
+	}
 	if len(cfg.Instances) == 0 {
 		return nil, errors.New("Instances ist leer")
 	}
+	wl := cfg.workload()
+	if err := wl.Validate(); err != nil {
+		return nil, err
+	}
+	if c := cfg.Calibration; c != nil {
+		cfg.Warmup = c.RecommendedWarmup(cfg.Warmup)
+		if floor := int(math.Ceil(4 * c.MemAvailStddevMiB)); cfg.Precheck.MaxMemDropMiB > 0 && floor > cfg.Precheck.MaxMemDropMiB {
+			cfg.Precheck.MaxMemDropMiB = floor
+		}
+	}
+
+	if wl.Ballooning() {
+		for _, mem := range append([]int{cfg.MemMiB}, cfg.MemSizes...) {
+			if err := wl.ValidateBalloon(mem, cfg.Warmup); err != nil {
+				return nil, err
+			}
+		}
+	}
 
 	if err := os.MkdirAll(cfg.OutDir, 0o755); err != nil {
 		return nil, err
 	}
 
 	res := &RunResult{
-		StartedAt: time.Now(),
+		StartedAt: cfg.Clock.Now(),
 		Profile:   cfg.Profile,
+		Host:      ReadHostInfo(),
+		Workload:  &wl,
+	}
+	if cfg.ReserveMiB == ReserveAuto {
+		cfg.ReserveMiB = DefaultReserveMiB(res.Host.MemTotalKB)
+	}
+	res.ReserveMiB = cfg.ReserveMiB
+	if cfg.Calibration != nil {
+		res.NoiseFloorMiB = cfg.Calibration.NoiseFloorMiB()
+	}
+
+	base := captureBaseline(cfg.KSMPath)
+
+	mems := cfg.MemSizes
+	if len(mems) == 0 {
+		mems = []int{cfg.MemMiB}
 	}
 
-	for _, n := range cfg.Instances {
-		if n <= 0 {
+	swap, swapCleanup := prepareSwap(cfg, mems)
+	defer swapCleanup()
+	res.Swap = swap
+	for _, mem := range mems {
+		if mem <= 0 {
 			continue
 		}
-
-		step := StepResult{
-			N:       n,
-			Profile: cfg.Profile,
-			MemMiB:  cfg.MemMiB,
-			Warmup:  cfg.Warmup,
+		stepCfg := cfg
+		stepCfg.MemMiB = mem
+		for _, n := range cfg.Instances {
+			if n <= 0 {
+				continue
+			}
+			step, err := runStep(ctx, stepCfg, base, n)
+			if err != nil {
+				return res, err
+			}
+			res.Steps = append(res.Steps, step)
+			if res.Swap != nil && step.SwapUsedMiB > res.Swap.PeakMiB {
+				res.Swap.PeakMiB = step.SwapUsedMiB
+			}
 		}
+	}
+
+	// Write JSON
+	jPath := filepath.Join(cfg.OutDir, fmt.Sprintf("bench_%s_%s.json", strings.ToLower(string(cfg.Profile)), cfg.Clock.Now().Format("20060102_150405")))
+	if err := writeJSON(jPath, res); err != nil {
+		return res, err
+	}
 
-		preMem, _ := ksm.ReadMemInfo()
-		preK, _ := ksm.Status(cfg.KSMPath)
-		step.PreMemKB = preMem
-		step.PreKSM = preK
+	// Write Markdown summary
+	mdPath := filepath.Join(cfg.OutDir, "report.md")
+	_ = os.WriteFile(mdPath, []byte(renderMarkdown(res)), 0o644)
 
-		ksmdBefore, _ := readKsmdTicks()
+	return res, nil
+}
 
-		cmds, err := startHogs(ctx, cfg, n)
-		if err != nil {
-			step.Notes = "Startfehler: " + err.Error()
-			res.Steps = append(res.Steps, step)
-			continue
-		}
+// runStep führt einen Step mit n Instanzen à cfg.MemMiB aus. Ein Fehler wird nur bei
+// Abbruch (ctx) zurückgegeben; Startprobleme landen in den Notes des Steps.
+func runStep(ctx context.Context, cfg Config, base hostBaseline, n int) (StepResult, error) {
+	step := StepResult{
+		N:       n,
+		Profile: cfg.Profile,
+		MemMiB:  cfg.MemMiB,
+		Warmup:  cfg.Warmup,
+	}
 
-		// Warmup – KSM braucht Zeit zum Scannen/Mergen.
-		select {
-		case <-ctx.Done():
-			_ = stopHogs(cmds)
-			return res, ctx.Err()
-		case <-time.After(cfg.Warmup):
-		}
+	if msg := checkReserve(cfg, n); msg != "" {
+		step.Skipped = true
+		step.Notes = msg
+		return step, nil
+	}
+
+	step.PrecheckViolations = precheck(ctx, cfg, base)
 
-		alive := countAlive(cmds)
-		step.Alive = alive
+	preMem, _ := ksm.ReadMemInfo()
+	preK, _ := ksm.Status(cfg.KSMPath)
+	step.PreMemKB = preMem
+	step.PreKSM = preK
 
-		postMem, _ := ksm.ReadMemInfo()
-		postK, _ := ksm.Status(cfg.KSMPath)
-		step.PostMemKB = postMem
-		step.PostKSM = postK
+	ksmdBefore, _ := ksm.ReadKsmdTicks()
+	cgPre := readCgroupMemoryCurrent()
 
-		ksmdAfter, _ := readKsmdTicks()
-		if ksmdBefore > 0 && ksmdAfter > 0 && ksmdAfter >= ksmdBefore {
-			step.KsmdTicksDelta = ksmdAfter - ksmdBefore
+	if cfg.workload().Mode == ModeFileShared {
+		path := sharedFilePath(cfg.OutDir, cfg.MemMiB)
+		if err := writeSharedFile(path, cfg.MemMiB, cfg.workload().Pattern); err != nil {
+			step.Notes = "Startfehler: " + err.Error()
+			return step, nil
 		}
+		defer os.Remove(path)
+	}
 
-		step.EstimatedSavedMiB = estimateSavedMiB(postK)
+	cmds, reports, err := startHogs(ctx, cfg, n)
+	if err != nil {
+		step.Notes = "Startfehler: " + err.Error()
+		return step, nil
+	}
 
-		// Cleanup
+	// Warmup – KSM braucht Zeit zum Scannen/Mergen.
+	if err := warmup(ctx, cfg, &step, reports, n); err != nil {
 		_ = stopHogs(cmds)
+		reports.wait()
+		return step, err
+	}
+
+	alive := countAlive(cmds)
+	step.Alive = alive
+	step.Instances = reports.snapshot(n)
+
+	postMem, _ := ksm.ReadMemInfo()
+	postK, _ := ksm.Status(cfg.KSMPath)
+	step.PostMemKB = postMem
+	step.SwapUsedMiB = swapUsedMiB(postMem)
+	step.PostKSM = postK
 
-		step.Duration = cfg.Warmup
-		res.Steps = append(res.Steps, step)
+	ksmdAfter, _ := ksm.ReadKsmdTicks()
+	if ksmdBefore > 0 && ksmdAfter > 0 && ksmdAfter >= ksmdBefore {
+		step.KsmdTicksDelta = ksmdAfter - ksmdBefore
 	}
 
-	// Write JSON
-	jPath := filepath.Join(cfg.OutDir, fmt.Sprintf("bench_%s_%s.json", strings.ToLower(string(cfg.Profile)), time.Now().Format("20060102_150405")))
-	if err := writeJSON(jPath, res); err != nil {
-		return res, err
+	var children []int
+	for _, r := range step.Instances {
+		children = append(children, r.ChildPIDs...)
+	}
+	rss, pss := sumSmapsRollup(cmds, children)
+	step.Estimates = estimateAll(estimateInput{
+		N:          n * (1 + cfg.workload().ForkChildren),
+		MemMiB:     cfg.MemMiB,
+		PostKSM:    postK,
+		RSSKB:      rss,
+		PSSKB:      pss,
+		CgroupPre:  cgPre,
+		CgroupPost: readCgroupMemoryCurrent(),
+	})
+	step.EstimatedSavedMiB, step.Estimator = pickEstimate(step.Estimates, cfg.Estimator)
+	if cfg.workload().ForkChildren > 0 {
+		step.Sharing = forkBreakdown(step.Estimates)
+	}
+	if step.Estimator != cfg.Estimator {
+		step.Notes = fmt.Sprintf("Schätzer %s nicht verfügbar, Fallback %s", cfg.Estimator, step.Estimator)
 	}
 
-	// Write Markdown summary
-	mdPath := filepath.Join(cfg.OutDir, "report.md")
-	_ = os.WriteFile(mdPath, []byte(renderMarkdown(res)), 0o644)
+	// Cleanup
+	_ = stopHogs(cmds)
+	reports.wait()
 
-	return res, nil
+	step.Duration = cfg.Warmup
+	return step, nil
 }
 
-func startHogs(ctx context.Context, cfg Config, n int) ([]*exec.Cmd, error) {
-	cmds := make([]*exec.Cmd, 0, n)
+// warmup wartet cfg.Warmup ab. Bei Ballooning wird dabei im Takt der Hog-Reports
+// eine Timeline aufgezeichnet, um zu sehen, wie schnell KSM Sharing gewinnt oder verliert.
+func warmup(ctx context.Context, cfg Config, step *StepResult, reports *hogReports, n int) error {
+	done := cfg.Clock.After(cfg.Warmup)
+	if !cfg.workload().Ballooning() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-done:
+			return nil
+		}
+	}
 
-	// Profile -> dirty behavior:
-	// P1: 0% unique, no redirty
-	// P2: 5% unique
-	// P3: 50% unique + periodic re-dirty (1s default)
-	var dirtyPct float64
-	var redirty time.Duration
-	switch cfg.Profile {
-	case ProfileP1:
-		dirtyPct = 0
-		redirty = 0
-	case ProfileP2:
-		dirtyPct = 5
-		redirty = 0
-	case ProfileP3:
-		dirtyPct = 50
-		if cfg.Interval > 0 {
-			redirty = cfg.Interval
-		} else {
-			redirty = 1 * time.Second
+	start := cfg.Clock.Now()
+	t := cfg.Clock.NewTicker(HogReportInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-done:
+			return nil
+		case <-t.C():
+			k, _ := ksm.Status(cfg.KSMPath)
+			smp := TimelineSample{
+				Offset:       cfg.Clock.Now().Sub(start),
+				PagesShared:  k["pages_shared"],
+				PagesSharing: k["pages_sharing"],
+			}
+			for _, r := range reports.snapshot(n) {
+				smp.RSSKB += r.RSSKB
+			}
+			step.Timeline = append(step.Timeline, smp)
 		}
-	default:
-		dirtyPct = 0
-		redirty = 0
+	}
+}
+
+// startHogs startet n Hog-Prozesse. Jeder bekommt eine Pipe (fd 3) für seine Selbstauskunft.
+func startHogs(ctx context.Context, cfg Config, n int) ([]*exec.Cmd, *hogReports, error) {
+	cmds := make([]*exec.Cmd, 0, n)
+	reports := newHogReports()
+
+	spec := cfg.workload()
+	pattern := spec.Pattern
+	if pattern == "" {
+		pattern = PatternConst
 	}
 
 	for i := 0; i < n; i++ {
@@ -199,26 +426,62 @@ func startHogs(ctx context.Context, cfg Config, n int) ([]*exec.Cmd, error) {
 			"__hog",
 			"--mem-mib", strconv.Itoa(cfg.MemMiB),
 			"--id", strconv.Itoa(i),
-			"--dirty-pct", fmt.Sprintf("%.2f", dirtyPct),
-			"--redirty-ms", strconv.Itoa(int(redirty.Milliseconds())),
+			"--dirty-pct", fmt.Sprintf("%.2f", spec.DirtyPct),
+			"--redirty-ms", strconv.Itoa(int(spec.Redirty.Milliseconds())),
+			"--pattern", pattern,
+			"--seed", strconv.Itoa(spec.SeedFor(i, n)),
+			"--workers", strconv.Itoa(max(spec.Workers, 1)),
+			"--jitter-pct", fmt.Sprintf("%.1f", spec.JitterPct),
+			"--rate-pps", strconv.Itoa(spec.RatePPS),
+			"--report-fd", "3",
+			"--report-ms", strconv.Itoa(int(HogReportInterval.Milliseconds())),
 		)
+		if len(spec.Madvise) > 0 {
+			cmd.Args = append(cmd.Args, "--madvise", strings.Join(spec.Madvise, ","))
+		}
+		if spec.ForkChildren > 0 {
+			cmd.Args = append(cmd.Args, "--fork-children", strconv.Itoa(spec.ForkChildren))
+		}
+		if spec.Mode == ModeFileShared {
+			cmd.Args = append(cmd.Args, "--mode", ModeFileShared, "--shared-file", sharedFilePath(cfg.OutDir, cfg.MemMiB))
+		}
+		if spec.GrowTo != nil {
+			cmd.Args = append(cmd.Args, "--grow-to", spec.GrowTo.String())
+		}
+		if spec.ShrinkTo != nil {
+			cmd.Args = append(cmd.Args, "--shrink-to", spec.ShrinkTo.String())
+		}
 		cmd.Stdout = nil
 		cmd.Stderr = nil
 
+		pr, pw, err := os.Pipe()
+		if err != nil {
+			_ = stopHogs(cmds)
+			reports.wait()
+			return nil, nil, err
+		}
+		cmd.ExtraFiles = []*os.File{pw}
+
 		// Start
-		if err := cmd.Start(); err != nil {
+		err = cmd.Start()
+		pw.Close() // Schreibende gehört jetzt dem Hog; EOF, sobald er endet.
+		if err != nil {
+			pr.Close()
 			// Stop already started ones
 			_ = stopHogs(cmds)
-			return nil, err
+			reports.wait()
+			return nil, nil, err
 		}
+		reports.consume(pr)
 		cmds = append(cmds, cmd)
 	}
 
-	return cmds, nil
+	return cmds, reports, nil
 }
 
 func stopHogs(cmds []*exec.Cmd) error {
 	// Try SIGTERM, then SIGKILL.
+	// Bewusst echte Zeit statt cfg.Clock: hier wird auf reale Prozesse gewartet.
 	for _, c := range cmds {
 		if c == nil || c.Process == nil {
 			continue
@@ -271,7 +534,8 @@ func countAlive(cmds []*exec.Cmd) int {
 	return alive
 }
 
-func estimateSavedMiB(ksmStats map[string]int64) float64 {
+// EstimateSavedMiB schätzt die Ersparnis aus pages_sharing - pages_shared.
+func EstimateSavedMiB(ksmStats map[string]int64) float64 {
 	if ksmStats == nil {
 		return 0
 	}
@@ -298,18 +562,62 @@ func renderMarkdown(r *RunResult) string {
 	var b strings.Builder
 	b.WriteString("# DENSITY Bench Report\n\n")
 	b.WriteString(fmt.Sprintf("- Zeitpunkt: %s\n", r.StartedAt.Format(time.RFC3339)))
-	b.WriteString(fmt.Sprintf("- Profil: %s\n\n", r.Profile))
+	b.WriteString(fmt.Sprintf("- Profil: %s\n", r.Profile))
+	if w := r.Workload; w != nil {
+		b.WriteString(fmt.Sprintf("- Workload: %.1f%% individuell, Redirty %s, Muster %s", w.DirtyPct, w.Redirty, w.Pattern))
+		if w.Mode != "" && w.Mode != ModeAnon {
+			b.WriteString(", Modus " + w.Mode)
+		}
+		if w.ForkChildren > 0 {
+			b.WriteString(fmt.Sprintf(", Fork-Cluster 1+%d", w.ForkChildren))
+		}
+		if len(w.Madvise) > 0 {
+			b.WriteString(", madvise " + strings.Join(w.Madvise, ","))
+		}
+		if w.Redirty > 0 && (w.Workers > 1 || w.JitterPct > 0 || w.RatePPS > 0) {
+			b.WriteString(fmt.Sprintf(" (%d Goroutinen, Jitter %.0f%%", max(w.Workers, 1), w.JitterPct))
+			if w.RatePPS > 0 {
+				b.WriteString(fmt.Sprintf(", max. %d Pages/s", w.RatePPS))
+			}
+			b.WriteString(")")
+		}
+		b.WriteString("\n")
+	}
+	if r.ReserveMiB > 0 {
+		b.WriteString(fmt.Sprintf("- Host-Reserve: %d MiB\n", r.ReserveMiB))
+	}
+	b.WriteString("\n")
 
 	b.WriteString("| N | Alive | Saved (MiB) | ksmd ticks Δ | MemAvailable vorher (MiB) | MemAvailable nachher (MiB) |\n")
 	b.WriteString("|---:|---:|---:|---:|---:|---:|\n")
 	for _, s := range r.Steps {
+		if s.Skipped {
+			b.WriteString(fmt.Sprintf("| %d | – | übersprungen | – | – | – |\n", s.N))
+			continue
+		}
 		preAvail := memMiB(s.PreMemKB, "MemAvailable")
 		postAvail := memMiB(s.PostMemKB, "MemAvailable")
 		b.WriteString(fmt.Sprintf("| %d | %d | %.1f | %d | %.1f | %.1f |\n",
 			s.N, s.Alive, s.EstimatedSavedMiB, s.KsmdTicksDelta, preAvail, postAvail))
 	}
 	b.WriteString("\n")
-	b.WriteString("**Hinweis:** Der geschätzte \"Saved\"-Wert basiert auf KSM-Statistiken (pages_sharing/pages_shared) und ist workload-abhängig.\n")
+	b.WriteString(renderMemMatrix(r))
+	b.WriteString(renderEstimatorTable(r))
+	b.WriteString(renderReserve(r))
+	b.WriteString(renderSwap(r))
+	b.WriteString(renderModeNote(r))
+	b.WriteString(renderForkBreakdown(r))
+	b.WriteString(renderMadvise(r))
+	b.WriteString(renderTimeline(r))
+	b.WriteString(renderPrecheckViolations(r))
+	if r.NoiseFloorMiB > 0 {
+		b.WriteString(fmt.Sprintf("**Rauschuntergrenze (Kalibrierung):** ±%.1f MiB – kleinere Ersparnisse sind nicht signifikant.\n\n", r.NoiseFloorMiB))
+	}
+	est := EstimatorPagesSharing
+	if len(r.Steps) > 0 && r.Steps[0].Estimator != "" {
+		est = r.Steps[0].Estimator
+	}
+	b.WriteString(fmt.Sprintf("**Hinweis:** Der geschätzte \"Saved\"-Wert basiert auf dem Schätzer `%s` und ist workload-abhängig.\n", est))
 	return b.String()
 }
 
@@ -321,78 +629,6 @@ func memMiB(m map[string]uint64, key string) float64 {
 	return float64(kb) / 1024.0
 }
 
-func readKsmdTicks() (int64, error) {
-	pid, err := findPIDByComm("ksmd")
-	if err != nil {
-		return 0, err
-	}
-	statPath := filepath.Join("/proc", strconv.Itoa(pid), "stat")
-	b, err := os.ReadFile(statPath)
-	if err != nil {
-		return 0, err
-	}
-	// /proc/[pid]/stat: fields are space-separated, but field 2 can contain spaces in parentheses.
-	// We'll parse carefully.
-	utime, stime, err := parseProcStatUtimeStime(string(b))
-	if err != nil {
-		return 0, err
-	}
-	return utime + stime, nil
-}
-
-func findPIDByComm(comm string) (int, error) {
-	d, err := os.ReadDir("/proc")
-	if err != nil {
-		return 0, err
-	}
-	for _, e := range d {
-		if !e.IsDir() {
-			continue
-		}
-		pid, err := strconv.Atoi(e.Name())
-		if err != nil {
-			continue
-		}
-		cpath := filepath.Join("/proc", e.Name(), "comm")
-		b, err := os.ReadFile(cpath)
-		if err != nil {
-			continue
-		}
-		name := strings.TrimSpace(string(b))
-		if name == comm {
-			return pid, nil
-		}
-	}
-	return 0, fmt.Errorf("process %q nicht gefunden", comm)
-}
-
-func parseProcStatUtimeStime(stat string) (int64, int64, error) {
-	// Find the last ')' which ends comm field.
-	i := strings.LastIndex(stat, ")")
-	if i < 0 {
-		return 0, 0, fmt.Errorf("unexpected /proc/stat format")
-	}
-	after := strings.Fields(stat[i+1:])
-	// utime is field 14, stime field 15 in the original format.
-	// After stripping pid+comm, the offset changes.
-	// Original fields:
-	// 1 pid, 2 comm, 3 state, 4 ppid, ... 14 utime, 15 stime
-	// After comm removed, after[0] = state (field 3).
-	// Thus utime (14) -> after index (14-3) = 11, stime -> 12
-	if len(after) < 13 {
-		return 0, 0, fmt.Errorf("unexpected /proc/stat fields")
-	}
-	ut, err := strconv.ParseInt(after[11], 10, 64)
-	if err != nil {
-		return 0, 0, err
-	}
-	st, err := strconv.ParseInt(after[12], 10, 64)
-	if err != nil {
-		return 0, 0, err
-	}
-	return ut, st, nil
-}
-
 // Optional: read simple vmstat counters (pswpin/pswpout).
 func ReadVMStat() (map[string]uint64, error) {
 	f, err := os.Open("/proc/vmstat")
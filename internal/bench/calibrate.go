@@ -0,0 +1,167 @@
+package bench
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	"github.com/LglzNL/density/internal/clock"
+	"github.com/LglzNL/density/internal/ksm"
+	"github.com/LglzNL/density/internal/stats"
+)
+
+// CalibrationFile ist der Default-Dateiname im Output-Verzeichnis.
+const CalibrationFile = "calibration.json"
+
+// CalibrateConfig steuert die Leerlaufmessung.
+type CalibrateConfig struct {
+	KSMPath  string
+	Duration time.Duration
+	Interval time.Duration
+
+	Clock clock.Clock // nil = clock.Real
+}
+
+// Calibration beschreibt das Grundrauschen eines ruhenden Hosts. Spätere Runs nutzen es,
+// um Warmup, Precheck-Schwellen und Fehlerbalken zu dimensionieren.
+type Calibration struct {
+	CreatedAt time.Time     `json:"created_at"`
+	Duration  time.Duration `json:"duration"`
+	Samples   int           `json:"samples"`
+	Host      *HostInfo     `json:"host,omitempty"`
+
+	MemAvailStddevMiB float64 `json:"mem_avail_stddev_mib"`
+	MemAvailRangeMiB  float64 `json:"mem_avail_range_mib"`
+
+	KsmdTicksPerSec    float64 `json:"ksmd_ticks_per_sec"`
+	PagesSharingPerSec float64 `json:"pages_sharing_per_sec"`
+	FullScanSec        float64 `json:"full_scan_sec,omitempty"` // 0 = kein vollständiger Scan beobachtet
+	KSMRunning         bool    `json:"ksm_running"`
+	PagesToScan        int64   `json:"pages_to_scan,omitempty"`
+	SleepMillisecs     int64   `json:"sleep_millisecs,omitempty"`
+}
+
+// NoiseFloorMiB ist die Ersparnis, unterhalb derer ein Messwert nicht vom Rauschen
+// zu unterscheiden ist (2σ der MemAvailable-Schwankung).
+func (c *Calibration) NoiseFloorMiB() float64 {
+	if c == nil {
+		return 0
+	}
+	return 2 * c.MemAvailStddevMiB
+}
+
+// RecommendedWarmup: mindestens zwei vollständige KSM-Scans, sonst der übergebene Wert.
+func (c *Calibration) RecommendedWarmup(min time.Duration) time.Duration {
+	if c == nil || c.FullScanSec <= 0 {
+		return min
+	}
+	w := time.Duration(2 * c.FullScanSec * float64(time.Second))
+	if w > min {
+		return w.Round(time.Second)
+	}
+	return min
+}
+
+// Calibrate misst MemAvailable-Jitter und Hintergrundaktivität von ksmd ohne Last.
+func Calibrate(ctx context.Context, cfg CalibrateConfig) (*Calibration, error) {
+	if cfg.KSMPath == "" {
+		cfg.KSMPath = ksm.DefaultPath
+	}
+	if cfg.Duration <= 0 {
+		cfg.Duration = 3 * time.Minute
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Second
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = clock.Real
+	}
+	clk := cfg.Clock
+
+	cal := &Calibration{
+		CreatedAt: clk.Now(),
+		Host:      ReadHostInfo(),
+	}
+
+	firstK, _ := ksm.Status(cfg.KSMPath)
+	ticksStart, _ := ksm.ReadKsmdTicks()
+	start := clk.Now()
+
+	var avail []float64
+	var scanStart time.Time
+	lastScans, haveScans := firstK["full_scans"]
+	tick := clk.NewTicker(cfg.Interval)
+	defer tick.Stop()
+	deadline := clk.After(cfg.Duration)
+
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-deadline:
+			break loop
+		case <-tick.C():
+		}
+
+		if mi, err := ksm.ReadMemInfo(); err == nil {
+			avail = append(avail, float64(mi["MemAvailable"])/1024.0)
+		}
+		if !haveScans {
+			continue
+		}
+		if v, err := ksm.ReadInt(cfg.KSMPath, "full_scans"); err == nil && v > lastScans {
+			now := clk.Now()
+			// Erst ab dem zweiten Wechsel kennen wir die Dauer eines vollständigen Scans.
+			if !scanStart.IsZero() {
+				cal.FullScanSec = now.Sub(scanStart).Seconds() / float64(v-lastScans)
+			}
+			scanStart = now
+			lastScans = v
+		}
+	}
+
+	elapsed := clk.Now().Sub(start)
+	cal.Duration = elapsed.Round(time.Second)
+	cal.Samples = len(avail)
+	if len(avail) < 2 {
+		return nil, fmt.Errorf("zu wenige Messpunkte (%d) – Dauer/Intervall prüfen", len(avail))
+	}
+
+	sum := stats.Summarize(avail)
+	cal.MemAvailStddevMiB = sum.Stddev
+	cal.MemAvailRangeMiB = sum.Max - sum.Min
+
+	if ticksEnd, err := ksm.ReadKsmdTicks(); err == nil && ticksStart > 0 && ticksEnd >= ticksStart {
+		cal.KsmdTicksPerSec = float64(ticksEnd-ticksStart) / elapsed.Seconds()
+	}
+	lastK, _ := ksm.Status(cfg.KSMPath)
+	cal.KSMRunning = lastK["run"] == 1
+	cal.PagesToScan = lastK["pages_to_scan"]
+	cal.SleepMillisecs = lastK["sleep_millisecs"]
+	if d := lastK["pages_sharing"] - firstK["pages_sharing"]; d != 0 {
+		cal.PagesSharingPerSec = math.Round(float64(d)/elapsed.Seconds()*100) / 100
+	}
+	return cal, nil
+}
+
+// SaveCalibration schreibt die Kalibrierung als JSON.
+func SaveCalibration(path string, c *Calibration) error {
+	return writeJSON(path, c)
+}
+
+// LoadCalibration liest eine gespeicherte Kalibrierung.
+func LoadCalibration(path string) (*Calibration, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c Calibration
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &c, nil
+}
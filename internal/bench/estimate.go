@@ -0,0 +1,209 @@
+package bench
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Estimator ist eine Strategie, die eingesparten Speicher zu schätzen.
+type Estimator string
+
+const (
+	// EstimatorPagesSharing: (pages_sharing - pages_shared) * Pagesize (klassische Formel).
+	EstimatorPagesSharing Estimator = "pages_sharing"
+	// EstimatorGeneralProfit: KSM general_profit (Kernel >= 6.1), berücksichtigt Metadaten-Kosten.
+	EstimatorGeneralProfit Estimator = "general_profit"
+	// EstimatorPSSDelta: Σ RSS - Σ PSS der Hog-Prozesse (smaps_rollup).
+	EstimatorPSSDelta Estimator = "pss_delta"
+	// EstimatorCgroupDelta: erwarteter Footprint - Δ memory.current der eigenen cgroup (v2).
+	EstimatorCgroupDelta Estimator = "cgroup_delta"
+)
+
+// Estimators listet alle Schätzer in Ausgabereihenfolge.
+var Estimators = []Estimator{EstimatorPagesSharing, EstimatorGeneralProfit, EstimatorPSSDelta, EstimatorCgroupDelta}
+
+// ParseEstimator validiert einen Schätzer-Namen.
+func ParseEstimator(s string) (Estimator, error) {
+	for _, e := range Estimators {
+		if string(e) == s {
+			return e, nil
+		}
+	}
+	return "", fmt.Errorf("unbekannter Schätzer %q", s)
+}
+
+// estimateInput sammelt alles, was die Schätzer für einen Step brauchen.
+type estimateInput struct {
+	N      int
+	MemMiB int
+
+	PostKSM map[string]int64
+
+	RSSKB, PSSKB uint64 // Summe über alle Hogs; 0 = nicht verfügbar
+
+	CgroupPre, CgroupPost int64 // memory.current in Bytes; <0 = nicht verfügbar
+}
+
+// estimateAll berechnet jeden verfügbaren Schätzer (MiB). Nicht verfügbare fehlen in der Map.
+func estimateAll(in estimateInput) map[string]float64 {
+	const mib = 1024.0 * 1024.0
+	out := map[string]float64{}
+
+	if _, ok := in.PostKSM["pages_sharing"]; ok {
+		out[string(EstimatorPagesSharing)] = EstimateSavedMiB(in.PostKSM)
+	}
+	if gp, ok := in.PostKSM["general_profit"]; ok {
+		out[string(EstimatorGeneralProfit)] = float64(gp) / mib
+	}
+	if in.RSSKB > 0 && in.PSSKB > 0 && in.RSSKB >= in.PSSKB {
+		out[string(EstimatorPSSDelta)] = float64(in.RSSKB-in.PSSKB) / 1024.0
+	}
+	if in.CgroupPre >= 0 && in.CgroupPost >= 0 {
+		expected := float64(in.N) * float64(in.MemMiB)
+		used := float64(in.CgroupPost-in.CgroupPre) / mib
+		if saved := expected - used; saved > 0 {
+			out[string(EstimatorCgroupDelta)] = saved
+		} else {
+			out[string(EstimatorCgroupDelta)] = 0
+		}
+	}
+	return out
+}
+
+// pickEstimate liefert den Wert des gewählten Schätzers; fehlt er, wird auf
+// pages_sharing zurückgefallen und das im zweiten Rückgabewert vermerkt.
+func pickEstimate(est map[string]float64, want Estimator) (float64, Estimator) {
+	if v, ok := est[string(want)]; ok {
+		return v, want
+	}
+	return est[string(EstimatorPagesSharing)], EstimatorPagesSharing
+}
+
+// sumSmapsRollup summiert Rss/Pss (kB) aller laufenden Hogs und zusätzlicher PIDs (Fork-Kinder).
+func sumSmapsRollup(cmds []*exec.Cmd, extra []int) (rss, pss uint64) {
+	pids := append([]int(nil), extra...)
+	for _, c := range cmds {
+		if c == nil || c.Process == nil {
+			continue
+		}
+		pids = append(pids, c.Process.Pid)
+	}
+	for _, pid := range pids {
+		r, p, err := readSmapsRollup(pid)
+		if err != nil {
+			continue
+		}
+		rss += r
+		pss += p
+	}
+	return rss, pss
+}
+
+func readSmapsRollup(pid int) (rss, pss uint64, err error) {
+	f, err := os.Open(filepath.Join("/proc", strconv.Itoa(pid), "smaps_rollup"))
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		parts := strings.Fields(sc.Text())
+		if len(parts) < 2 {
+			continue
+		}
+		v, err := strconv.ParseUint(parts[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		switch parts[0] {
+		case "Rss:":
+			rss = v
+		case "Pss:":
+			pss = v
+		}
+	}
+	return rss, pss, sc.Err()
+}
+
+// readCgroupMemoryCurrent liest memory.current der cgroup (v2) dieses Prozesses.
+// Hogs sind Kinder und landen in derselben cgroup. -1 = nicht verfügbar.
+func readCgroupMemoryCurrent() int64 {
+	b, err := os.ReadFile("/proc/self/cgroup")
+	if err != nil {
+		return -1
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		if !strings.HasPrefix(line, "0::") {
+			continue
+		}
+		p := filepath.Join("/sys/fs/cgroup", strings.TrimPrefix(line, "0::"), "memory.current")
+		v, err := os.ReadFile(p)
+		if err != nil {
+			return -1
+		}
+		n, err := strconv.ParseInt(strings.TrimSpace(string(v)), 10, 64)
+		if err != nil {
+			return -1
+		}
+		return n
+	}
+	return -1
+}
+
+// renderEstimatorTable stellt alle Schätzer je Step gegenüber, damit Abweichungen sichtbar werden.
+func renderEstimatorTable(r *RunResult) string {
+	seen := map[string]bool{}
+	for _, s := range r.Steps {
+		for k := range s.Estimates {
+			seen[k] = true
+		}
+	}
+	if len(seen) == 0 {
+		return ""
+	}
+	var cols []string
+	for _, e := range Estimators {
+		if seen[string(e)] {
+			cols = append(cols, string(e))
+			delete(seen, string(e))
+		}
+	}
+	rest := make([]string, 0, len(seen))
+	for k := range seen {
+		rest = append(rest, k)
+	}
+	sort.Strings(rest)
+	cols = append(cols, rest...)
+
+	var b strings.Builder
+	b.WriteString("## Abgleich der Schätzer (MiB)\n\n")
+	b.WriteString("| N |")
+	for _, c := range cols {
+		b.WriteString(" " + c + " |")
+	}
+	b.WriteString("\n|---:|")
+	for range cols {
+		b.WriteString("---:|")
+	}
+	b.WriteString("\n")
+	for _, s := range r.Steps {
+		b.WriteString(fmt.Sprintf("| %d |", s.N))
+		for _, c := range cols {
+			if v, ok := s.Estimates[c]; ok {
+				b.WriteString(fmt.Sprintf(" %.1f |", v))
+			} else {
+				b.WriteString(" – |")
+			}
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	return b.String()
+}
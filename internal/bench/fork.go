@@ -0,0 +1,50 @@
+package bench
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SharingBreakdown trennt bei Fork-Clustern das Sharing nach Herkunft (MiB).
+type SharingBreakdown struct {
+	TotalMiB     float64 `json:"total_mib"`     // Σ RSS - Σ PSS über Hogs und Kinder
+	KSMMiB       float64 `json:"ksm_mib"`       // durch KSM gemergt (pages_sharing-Formel)
+	InheritedMiB float64 `json:"inherited_mib"` // per fork() geerbt und noch nicht kopiert
+}
+
+// forkBreakdown leitet die Aufteilung aus den Schätzern ab. Ohne pss_delta ist keine
+// Aufteilung möglich (nil).
+func forkBreakdown(est map[string]float64) *SharingBreakdown {
+	total, ok := est[string(EstimatorPSSDelta)]
+	if !ok {
+		return nil
+	}
+	b := &SharingBreakdown{TotalMiB: total, KSMMiB: est[string(EstimatorPagesSharing)]}
+	if inh := total - b.KSMMiB; inh > 0 {
+		b.InheritedMiB = inh
+	}
+	return b
+}
+
+// renderForkBreakdown rendert die Aufteilung geerbt vs. KSM.
+func renderForkBreakdown(r *RunResult) string {
+	w := r.Workload
+	if w == nil || w.ForkChildren <= 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("## Fork-Cluster (1 Hog + %d Kinder pro Instanz)\n\n", w.ForkChildren))
+	b.WriteString("| N | Prozesse | Sharing gesamt (MiB) | davon geerbt/CoW (MiB) | davon KSM (MiB) |\n")
+	b.WriteString("|---:|---:|---:|---:|---:|\n")
+	for _, s := range r.Steps {
+		if s.Sharing == nil {
+			b.WriteString(fmt.Sprintf("| %d | %d | n/a | n/a | n/a |\n", s.N, s.N*(1+w.ForkChildren)))
+			continue
+		}
+		b.WriteString(fmt.Sprintf("| %d | %d | %.1f | %.1f | %.1f |\n",
+			s.N, s.N*(1+w.ForkChildren), s.Sharing.TotalMiB, s.Sharing.InheritedMiB, s.Sharing.KSMMiB))
+	}
+	b.WriteString("\nGeerbte Pages sind schon vor KSM geteilt; KSM kann nur Pages mergen, die nach dem fork() kopiert wurden\n")
+	b.WriteString("oder von vornherein getrennt waren. Ein hoher CoW-Anteil senkt den zusätzlichen Nutzen von KSM.\n\n")
+	return b.String()
+}
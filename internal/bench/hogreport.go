@@ -0,0 +1,74 @@
+package bench
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// HogReportInterval ist der Takt, in dem Hogs ihre Selbstauskunft senden.
+const HogReportInterval = 1 * time.Second
+
+// HogReport ist die Selbstauskunft einer Hog-Instanz (eine JSON-Zeile pro Intervall
+// über eine Pipe an den Bench-Prozess). Spart das Scrapen von /proc pro PID.
+type HogReport struct {
+	ID           int       `json:"id"`
+	Time         time.Time `json:"t"`
+	RSSKB        uint64    `json:"rss_kb"`
+	MergingPages int64     `json:"ksm_merging_pages"`        // aus /proc/self/ksm_stat
+	RmapItems    int64     `json:"ksm_rmap_items,omitempty"` // aus /proc/self/ksm_stat
+	Profit       int64     `json:"ksm_process_profit"`       // Bytes, aus /proc/self/ksm_stat
+	DirtyCycles  uint64    `json:"dirty_cycles"`             // abgeschlossene Redirty-Runden (über alle Goroutinen)
+	KsmStat      bool      `json:"ksm_stat"`
+	ChildPIDs    []int     `json:"child_pids,omitempty"` // Fork-Cluster: PIDs der Kinder                 // false: Kernel ohne ksm_stat, Felder leer
+}
+
+// hogReports sammelt die jeweils letzte Selbstauskunft pro Instanz.
+type hogReports struct {
+	mu     sync.Mutex
+	latest map[int]HogReport
+	wg     sync.WaitGroup
+}
+
+func newHogReports() *hogReports {
+	return &hogReports{latest: map[int]HogReport{}}
+}
+
+// consume liest Reports von r bis EOF (Hog beendet).
+func (h *hogReports) consume(r io.ReadCloser) {
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		defer r.Close()
+		sc := bufio.NewScanner(r)
+		for sc.Scan() {
+			var rep HogReport
+			if err := json.Unmarshal(sc.Bytes(), &rep); err != nil {
+				continue
+			}
+			h.mu.Lock()
+			h.latest[rep.ID] = rep
+			h.mu.Unlock()
+		}
+	}()
+}
+
+// snapshot liefert die letzten Reports, sortiert nach ID.
+func (h *hogReports) snapshot(n int) []HogReport {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	out := make([]HogReport, 0, len(h.latest))
+	for id := 0; id < n; id++ {
+		if r, ok := h.latest[id]; ok {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// wait wartet, bis alle Pipes geschlossen sind (nach stopHogs).
+func (h *hogReports) wait() {
+	h.wg.Wait()
+}
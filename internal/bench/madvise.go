@@ -0,0 +1,125 @@
+package bench
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MadviseFlag ist ein madvise(2)-Hinweis, den Hogs auf ihren Speicher anwenden können.
+type MadviseFlag struct {
+	Name   string
+	Advice int    // MADV_* (linux/mman.h)
+	Effect string // erwarteter Effekt auf Sharing, für den Report
+}
+
+// MadviseFlags listet die unterstützten Hinweise (Werte aus include/uapi/asm-generic/mman-common.h).
+var MadviseFlags = []MadviseFlag{
+	{"mergeable", 12, "Bereich für KSM registrieren; ohne das mergt KSM nur bei merge_any/prctl"},
+	{"unmergeable", 13, "Bereich von KSM abmelden, gemergte Pages werden wieder kopiert"},
+	{"dontfork", 10, "Kinder erben den Bereich nicht (kein CoW-Sharing, Kinder haben den Speicher nicht)"},
+	{"wipeonfork", 18, "Kinder sehen den Bereich genullt (kein CoW-Sharing, dafür Null-Pages)"},
+	{"hugepage", 14, "THP erlauben; KSM muss Huge Pages vor dem Mergen splitten"},
+	{"nohugepage", 15, "THP verbieten; Pages bleiben 4K und sind direkt mergebar"},
+}
+
+// LookupMadvise sucht einen Hinweis nach Namen.
+func LookupMadvise(name string) (MadviseFlag, bool) {
+	for _, f := range MadviseFlags {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return MadviseFlag{}, false
+}
+
+// ParseMadvise parst eine Komma-Liste wie "mergeable,wipeonfork".
+func ParseMadvise(s string) ([]string, error) {
+	var out []string
+	for _, p := range strings.Split(s, ",") {
+		p = strings.TrimSpace(strings.ToLower(p))
+		if p == "" {
+			continue
+		}
+		if _, ok := LookupMadvise(p); !ok {
+			names := make([]string, 0, len(MadviseFlags))
+			for _, f := range MadviseFlags {
+				names = append(names, f.Name)
+			}
+			return nil, fmt.Errorf("unbekannter madvise-Hinweis %q (%s)", p, strings.Join(names, "|"))
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// hasMadvise meldet, ob name in der Liste steht.
+func hasMadvise(list []string, name string) bool {
+	for _, x := range list {
+		if x == name {
+			return true
+		}
+	}
+	return false
+}
+
+// validateMadvise prüft widersprüchliche Kombinationen.
+func validateMadvise(list []string) error {
+	for _, pair := range [][2]string{{"mergeable", "unmergeable"}, {"hugepage", "nohugepage"}, {"dontfork", "wipeonfork"}} {
+		if hasMadvise(list, pair[0]) && hasMadvise(list, pair[1]) {
+			return fmt.Errorf("madvise: %s und %s schließen sich aus", pair[0], pair[1])
+		}
+	}
+	for _, x := range list {
+		if _, ok := LookupMadvise(x); !ok {
+			return fmt.Errorf("unbekannter madvise-Hinweis %q", x)
+		}
+	}
+	return nil
+}
+
+// renderMadvise dokumentiert die angewendeten Hinweise und die gemessene Wirkung.
+func renderMadvise(r *RunResult) string {
+	w := r.Workload
+	if w == nil || len(w.Madvise) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("## madvise\n\n")
+	b.WriteString("| Hinweis | erwarteter Effekt |\n")
+	b.WriteString("|---|---|\n")
+	for _, name := range w.Madvise {
+		f, _ := LookupMadvise(name)
+		b.WriteString(fmt.Sprintf("| `MADV_%s` | %s |\n", strings.ToUpper(f.Name), f.Effect))
+	}
+	b.WriteString("\n| N | KSM merging (Pages, Σ Hogs) | Saved (MiB) |")
+	fork := w.ForkChildren > 0
+	if fork {
+		b.WriteString(" geerbt/CoW (MiB) |")
+	}
+	b.WriteString("\n|---:|---:|---:|")
+	if fork {
+		b.WriteString("---:|")
+	}
+	b.WriteString("\n")
+	for _, s := range r.Steps {
+		var merging int64
+		for _, in := range s.Instances {
+			merging += in.MergingPages
+		}
+		b.WriteString(fmt.Sprintf("| %d | %d | %.1f |", s.N, merging, s.EstimatedSavedMiB))
+		if fork {
+			if s.Sharing != nil {
+				b.WriteString(fmt.Sprintf(" %.1f |", s.Sharing.InheritedMiB))
+			} else {
+				b.WriteString(" n/a |")
+			}
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	if fork && (hasMadvise(w.Madvise, "dontfork") || hasMadvise(w.Madvise, "wipeonfork")) {
+		b.WriteString("Mit `dontfork`/`wipeonfork` erben die Kinder den Speicher nicht; das geerbte Sharing sollte\n")
+		b.WriteString("gegen 0 gehen. Datenbanken (z.B. Redis-Snapshots per fork) schließen so Puffer vom CoW aus.\n\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,161 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LglzNL/density/internal/ksm"
+)
+
+// PrecheckConfig steuert die Umgebungsprüfung vor jedem Step.
+// Nullwerte deaktivieren die jeweilige Prüfung.
+type PrecheckConfig struct {
+	Enabled bool
+
+	// RequireKSMRun: run muss 1 sein (für "KSM an"-Runs). Ohne diese Option
+	// wird nur Drift gegenüber dem Zustand zu Run-Beginn gemeldet.
+	RequireKSMRun bool
+
+	// MaxLoad1: maximale 1-Minuten-Load vor einem Step.
+	MaxLoad1 float64
+	// MaxMemDropMiB: so viel darf MemAvailable seit Run-Beginn (ohne Hogs) sinken,
+	// bevor ein fremder Speicherverbraucher vermutet wird.
+	MaxMemDropMiB int
+
+	// Reapply schreibt abweichende KSM-Knobs auf den Stand zu Run-Beginn zurück.
+	Reapply bool
+	// WaitQuiet: so lange wird bei Load-/Speicher-Verletzungen gewartet, bis der Host ruhig ist.
+	WaitQuiet time.Duration
+}
+
+// hostBaseline ist der Zustand zu Run-Beginn, gegen den jeder Step geprüft wird.
+type hostBaseline struct {
+	knobs        map[string]int64
+	memAvailKB   uint64
+	hasMemAvail  bool
+	pollInterval time.Duration
+}
+
+func captureBaseline(ksmPath string) hostBaseline {
+	b := hostBaseline{knobs: ksm.ReadKnobs(ksmPath), pollInterval: 2 * time.Second}
+	if mi, err := ksm.ReadMemInfo(); err == nil {
+		b.memAvailKB, b.hasMemAvail = mi["MemAvailable"]
+	}
+	return b
+}
+
+// precheck prüft die Vorbedingungen eines Steps und liefert alle Verletzungen.
+// Mit Reapply/WaitQuiet wird versucht, sie vor dem Step zu beheben; was danach
+// noch verletzt ist (bzw. was behoben wurde), steht im Ergebnis.
+func precheck(ctx context.Context, cfg Config, base hostBaseline) []string {
+	pc := cfg.Precheck
+	if !pc.Enabled {
+		return nil
+	}
+	var out []string
+
+	out = append(out, checkKnobs(cfg.KSMPath, base, pc)...)
+
+	deadline := cfg.Clock.Now().Add(pc.WaitQuiet)
+	for {
+		noisy := checkQuiet(pc, base)
+		if len(noisy) == 0 {
+			return out
+		}
+		if pc.WaitQuiet <= 0 || cfg.Clock.Now().After(deadline) {
+			if pc.WaitQuiet > 0 {
+				for i := range noisy {
+					noisy[i] += fmt.Sprintf(" (nach %s Wartezeit)", pc.WaitQuiet)
+				}
+			}
+			return append(out, noisy...)
+		}
+		select {
+		case <-ctx.Done():
+			return append(out, noisy...)
+		case <-cfg.Clock.After(base.pollInterval):
+		}
+	}
+}
+
+func checkKnobs(ksmPath string, base hostBaseline, pc PrecheckConfig) []string {
+	var out []string
+	if pc.RequireKSMRun {
+		if v, err := ksm.ReadInt(ksmPath, "run"); err != nil || v != 1 {
+			out = append(out, fmt.Sprintf("KSM läuft nicht (run=%d)", v))
+		}
+	}
+	for _, k := range ksm.Knobs {
+		want, ok := base.knobs[k]
+		if !ok {
+			continue
+		}
+		got, err := ksm.ReadInt(ksmPath, k)
+		if err != nil || got == want {
+			continue
+		}
+		msg := fmt.Sprintf("Drift: %s=%d (Run-Beginn: %d)", k, got, want)
+		if pc.Reapply {
+			if err := ksm.WriteInt(ksmPath, k, want); err != nil {
+				msg += ", Zurücksetzen fehlgeschlagen: " + err.Error()
+			} else {
+				msg += ", zurückgesetzt"
+			}
+		}
+		out = append(out, msg)
+	}
+	return out
+}
+
+func checkQuiet(pc PrecheckConfig, base hostBaseline) []string {
+	var out []string
+	if pc.MaxLoad1 > 0 {
+		if l, err := readLoad1(); err == nil && l > pc.MaxLoad1 {
+			out = append(out, fmt.Sprintf("Load zu hoch: %.2f > %.2f", l, pc.MaxLoad1))
+		}
+	}
+	if pc.MaxMemDropMiB > 0 && base.hasMemAvail {
+		if mi, err := ksm.ReadMemInfo(); err == nil {
+			now := mi["MemAvailable"]
+			if now < base.memAvailKB {
+				drop := float64(base.memAvailKB-now) / 1024.0
+				if drop > float64(pc.MaxMemDropMiB) {
+					out = append(out, fmt.Sprintf("MemAvailable seit Run-Beginn um %.0f MiB gesunken (fremder Verbraucher?)", drop))
+				}
+			}
+		}
+	}
+	return out
+}
+
+func readLoad1() (float64, error) {
+	b, err := os.ReadFile("/proc/loadavg")
+	if err != nil {
+		return 0, err
+	}
+	f := strings.Fields(string(b))
+	if len(f) < 1 {
+		return 0, fmt.Errorf("unerwartetes /proc/loadavg-Format")
+	}
+	return strconv.ParseFloat(f[0], 64)
+}
+
+func renderPrecheckViolations(r *RunResult) string {
+	var b strings.Builder
+	for _, s := range r.Steps {
+		for _, v := range s.PrecheckViolations {
+			if b.Len() == 0 {
+				b.WriteString("## Umgebungsprüfung\n\n")
+			}
+			b.WriteString(fmt.Sprintf("- N=%d: %s\n", s.N, v))
+		}
+	}
+	if b.Len() > 0 {
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,145 @@
+package bench
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultProfilesPath ist der Default-Speicherort für benutzerdefinierte Profile.
+const DefaultProfilesPath = "/etc/density/profiles.json"
+
+// ProfileDef ist ein benannter Workload, wie er in der Profil-Datei steht.
+type ProfileDef struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description,omitempty"`
+	DirtyPct    float64 `json:"dirty_pct"`
+	Pattern     string  `json:"pattern,omitempty"`
+	RedirtyMs   int     `json:"redirty_ms,omitempty"`
+	// DupRatio: Anteil der Instanzen (0..1] mit identischem Basis-Inhalt; 0 = alle (wie 1).
+	DupRatio float64 `json:"dup_ratio,omitempty"`
+	// Redirty-Verteilung, siehe HogSpec.
+	Workers   int     `json:"redirty_workers,omitempty"`
+	JitterPct float64 `json:"redirty_jitter_pct,omitempty"`
+	RatePPS   int     `json:"redirty_rate_pps,omitempty"`
+
+	Builtin bool `json:"-"`
+}
+
+// Spec wandelt die Definition in ein HogSpec.
+func (d ProfileDef) Spec() HogSpec {
+	return HogSpec{
+		DirtyPct:  d.DirtyPct,
+		Redirty:   time.Duration(d.RedirtyMs) * time.Millisecond,
+		Pattern:   d.Pattern,
+		DupRatio:  d.DupRatio,
+		Workers:   d.Workers,
+		JitterPct: d.JitterPct,
+		RatePPS:   d.RatePPS,
+	}
+}
+
+var profileNameRe = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]{0,31}$`)
+
+// Registry enthält eingebaute und benutzerdefinierte Profile (Schlüssel: Name in Großbuchstaben).
+type Registry struct {
+	defs map[string]ProfileDef
+}
+
+// NewRegistry liefert eine Registry mit den eingebauten Profilen P1–P3.
+func NewRegistry() *Registry {
+	r := &Registry{defs: map[string]ProfileDef{}}
+	for _, p := range []struct {
+		p    Profile
+		desc string
+	}{
+		{ProfileP1, "identisch (maximaler KSM-Effekt)"},
+		{ProfileP2, "ähnlich (realistischer)"},
+		{ProfileP3, "worst case (divergent, Redirty)"},
+	} {
+		spec := ProfileSpec(p.p, 0)
+		r.defs[string(p.p)] = ProfileDef{
+			Name:        string(p.p),
+			Description: p.desc,
+			DirtyPct:    spec.DirtyPct,
+			Pattern:     spec.Pattern,
+			RedirtyMs:   int(spec.Redirty.Milliseconds()),
+			Builtin:     true,
+		}
+	}
+	return r
+}
+
+// Register validiert und fügt ein Profil hinzu. Eingebaute Profile können nicht überschrieben werden.
+func (r *Registry) Register(d ProfileDef) error {
+	if !profileNameRe.MatchString(d.Name) {
+		return fmt.Errorf("Profil %q: ungültiger Name (Buchstabe, dann bis zu 31 Zeichen A-Z0-9_-)", d.Name)
+	}
+	key := strings.ToUpper(d.Name)
+	if old, ok := r.defs[key]; ok && old.Builtin {
+		return fmt.Errorf("Profil %q: eingebaute Profile können nicht überschrieben werden", d.Name)
+	}
+	if d.RedirtyMs < 0 {
+		return fmt.Errorf("Profil %q: redirty_ms muss >= 0 sein", d.Name)
+	}
+	if err := d.Spec().Validate(); err != nil {
+		return fmt.Errorf("Profil %q: %w", d.Name, err)
+	}
+	d.Name = key
+	d.Builtin = false
+	r.defs[key] = d
+	return nil
+}
+
+// Lookup sucht ein Profil (Groß-/Kleinschreibung egal).
+func (r *Registry) Lookup(name string) (ProfileDef, error) {
+	d, ok := r.defs[strings.ToUpper(name)]
+	if !ok {
+		return ProfileDef{}, fmt.Errorf("unbekanntes Profil %q (siehe 'densityctl bench profiles')", name)
+	}
+	return d, nil
+}
+
+// List liefert alle Profile: eingebaute zuerst, dann alphabetisch.
+func (r *Registry) List() []ProfileDef {
+	out := make([]ProfileDef, 0, len(r.defs))
+	for _, d := range r.defs {
+		out = append(out, d)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Builtin != out[j].Builtin {
+			return out[i].Builtin
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// LoadProfiles liest benutzerdefinierte Profile (JSON-Array von ProfileDef) in eine neue Registry.
+// Eine fehlende Datei ist kein Fehler, wenn optional=true.
+func LoadProfiles(path string, optional bool) (*Registry, error) {
+	r := NewRegistry()
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && optional {
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var defs []ProfileDef
+	if err := json.Unmarshal(b, &defs); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for _, d := range defs {
+		if err := r.Register(d); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return r, nil
+}
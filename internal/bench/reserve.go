@@ -0,0 +1,102 @@
+package bench
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/LglzNL/density/internal/ksm"
+)
+
+// ReserveAuto lässt die Host-Reserve aus MemTotal ableiten (siehe DefaultReserveMiB).
+const ReserveAuto = -1
+
+// DefaultReserveMiB ist die Reserve für OS, sshd, kubelet und Monitoring:
+// 10 % von MemTotal, mindestens 512 MiB, höchstens 4 GiB.
+func DefaultReserveMiB(memTotalKB uint64) int {
+	r := int(memTotalKB / 1024 / 10)
+	if r < 512 {
+		r = 512
+	}
+	if r > 4096 {
+		r = 4096
+	}
+	return r
+}
+
+// FootprintMiB schätzt den Spitzen-Footprint einer Instanz à memMiB ohne KSM:
+// Ballooning-Spitze, plus die Pages, die Fork-Kinder per CoW kopieren.
+func (h HogSpec) FootprintMiB(memMiB int) float64 {
+	peak := float64(memMiB)
+	if h.GrowTo != nil && float64(h.GrowTo.MiB) > peak {
+		peak = float64(h.GrowTo.MiB)
+	}
+	if h.ForkChildren > 0 {
+		peak += peak * float64(h.ForkChildren) * h.DirtyPct / 100
+	}
+	return peak
+}
+
+// stepFootprintMiB ist der Footprint eines Steps mit n Instanzen (file-shared: Datei nur einmal).
+func (h HogSpec) stepFootprintMiB(memMiB, n int) float64 {
+	if h.Mode == ModeFileShared {
+		return float64(memMiB)
+	}
+	return float64(n) * h.FootprintMiB(memMiB)
+}
+
+// FitInstances liefert, wie viele Instanzen mit perInstanceMiB in availMiB passen,
+// ohne die Reserve anzutasten (ohne KSM-Ersparnis gerechnet).
+func FitInstances(availMiB, reserveMiB int, perInstanceMiB float64) int {
+	if perInstanceMiB <= 0 || availMiB <= reserveMiB {
+		return 0
+	}
+	return int(math.Floor(float64(availMiB-reserveMiB) / perInstanceMiB))
+}
+
+// checkReserve prüft vor einem Step, ob nach dem Start der Hogs noch ReserveMiB frei bleiben.
+// Ist Swap erlaubt, zählt freier Swap zum verfügbaren Speicher.
+// Leerer String = Step darf laufen.
+func checkReserve(cfg Config, n int) string {
+	if cfg.ReserveMiB <= 0 {
+		return ""
+	}
+	mi, err := ksm.ReadMemInfo()
+	if err != nil {
+		return ""
+	}
+	avail, ok := mi["MemAvailable"]
+	if !ok {
+		return ""
+	}
+	availMiB := int(avail / 1024)
+	if cfg.Swap.Allow || cfg.Swap.TempMiB > 0 {
+		availMiB += int(mi["SwapFree"] / 1024)
+	}
+	need := cfg.workload().stepFootprintMiB(cfg.MemMiB, n)
+	if float64(availMiB)-need >= float64(cfg.ReserveMiB) {
+		return ""
+	}
+	return fmt.Sprintf("übersprungen: %d × %d MiB (%.0f MiB) ließen weniger als die Reserve von %d MiB frei (MemAvailable %d MiB, passen würden %d)",
+		n, cfg.MemMiB, need, cfg.ReserveMiB, availMiB, FitInstances(availMiB, cfg.ReserveMiB, cfg.workload().FootprintMiB(cfg.MemMiB)))
+}
+
+// renderReserve listet Steps, die wegen der Host-Reserve nicht gelaufen sind.
+func renderReserve(r *RunResult) string {
+	var skipped []StepResult
+	for _, s := range r.Steps {
+		if s.Skipped {
+			skipped = append(skipped, s)
+		}
+	}
+	if len(skipped) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("## Übersprungene Steps (Reserve %d MiB)\n\n", r.ReserveMiB))
+	for _, s := range skipped {
+		b.WriteString(fmt.Sprintf("- N=%d, %d MiB: %s\n", s.N, s.MemMiB, s.Notes))
+	}
+	b.WriteString("\n")
+	return b.String()
+}
@@ -0,0 +1,191 @@
+package bench
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParseScale parst eine Skala für Instanzzahlen (oder andere positive Größen).
+//
+// Formate (kommagetrennt kombinierbar):
+//
+//	10            Einzelwert
+//	10..80        Bereich, Schritt 1
+//	10..80..10    Bereich mit Schritt
+//	80..10..10    absteigender Bereich
+//	log:1..256    logarithmisch, Verdopplung (1,2,4,…,256)
+//	log:1..1000..7  logarithmisch mit 7 Punkten
+//
+// Beispiel: "1,2,5,10..50..10". Doppelte Werte werden entfernt, die Reihenfolge bleibt.
+func ParseScale(s string) ([]int, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, fmt.Errorf("leere Skala")
+	}
+
+	var out []int
+	seen := map[int]bool{}
+	for _, tok := range strings.Split(s, ",") {
+		tok = strings.TrimSpace(tok)
+		if tok == "" {
+			return nil, fmt.Errorf("ungültige Skala %q: leerer Eintrag", s)
+		}
+		vals, err := parseScaleToken(tok)
+		if err != nil {
+			return nil, fmt.Errorf("ungültiger Skalen-Eintrag %q: %w", tok, err)
+		}
+		for _, v := range vals {
+			if !seen[v] {
+				seen[v] = true
+				out = append(out, v)
+			}
+		}
+	}
+	return out, nil
+}
+
+func parseScaleToken(tok string) ([]int, error) {
+	logScale := strings.HasPrefix(tok, "log:")
+	tok = strings.TrimPrefix(tok, "log:")
+
+	parts := strings.Split(tok, "..")
+	if len(parts) > 3 {
+		return nil, fmt.Errorf("erwartet Wert, min..max oder min..max..step")
+	}
+	nums := make([]int, len(parts))
+	for i, p := range parts {
+		n, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil {
+			return nil, fmt.Errorf("%q ist keine ganze Zahl", p)
+		}
+		nums[i] = n
+	}
+
+	if len(nums) == 1 {
+		if logScale {
+			return nil, fmt.Errorf("log: braucht einen Bereich (log:min..max)")
+		}
+		if nums[0] <= 0 {
+			return nil, fmt.Errorf("Wert muss > 0 sein")
+		}
+		return nums, nil
+	}
+
+	from, to := nums[0], nums[1]
+	if from <= 0 || to <= 0 {
+		return nil, fmt.Errorf("Grenzen müssen > 0 sein")
+	}
+	if logScale {
+		points := 0
+		if len(nums) == 3 {
+			points = nums[2]
+			if points < 2 {
+				return nil, fmt.Errorf("log: Anzahl Punkte muss >= 2 sein")
+			}
+		}
+		return logSpace(from, to, points), nil
+	}
+
+	step := 1
+	if len(nums) == 3 {
+		step = nums[2]
+		if step <= 0 {
+			return nil, fmt.Errorf("step muss > 0 sein (Richtung ergibt sich aus min/max)")
+		}
+	}
+	var out []int
+	if from <= to {
+		for i := from; i <= to; i += step {
+			out = append(out, i)
+		}
+	} else {
+		for i := from; i >= to; i -= step {
+			out = append(out, i)
+		}
+	}
+	return out, nil
+}
+
+// logSpace liefert logarithmisch verteilte Werte zwischen from und to (inklusive).
+// points=0 bedeutet Verdopplung.
+func logSpace(from, to, points int) []int {
+	desc := from > to
+	lo, hi := from, to
+	if desc {
+		lo, hi = to, from
+	}
+
+	var out []int
+	if points == 0 {
+		for v := lo; v <= hi; v *= 2 {
+			out = append(out, v)
+		}
+		if out[len(out)-1] != hi {
+			out = append(out, hi)
+		}
+	} else {
+		ratio := math.Log(float64(hi)/float64(lo)) / float64(points-1)
+		for i := 0; i < points; i++ {
+			v := int(math.Round(float64(lo) * math.Exp(ratio*float64(i))))
+			if len(out) == 0 || v != out[len(out)-1] {
+				out = append(out, v)
+			}
+		}
+	}
+	if desc {
+		for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+			out[i], out[j] = out[j], out[i]
+		}
+	}
+	return out
+}
+
+// renderMemMatrix rendert bei einem Speichergrößen-Sweep die Ersparnis als Matrix
+// (Zeilen: N, Spalten: MiB pro Instanz). Ohne Sweep leer.
+func renderMemMatrix(r *RunResult) string {
+	var ns, mems []int
+	seenN, seenM := map[int]bool{}, map[int]bool{}
+	cell := map[[2]int]float64{}
+	for _, s := range r.Steps {
+		if !seenN[s.N] {
+			seenN[s.N] = true
+			ns = append(ns, s.N)
+		}
+		if !seenM[s.MemMiB] {
+			seenM[s.MemMiB] = true
+			mems = append(mems, s.MemMiB)
+		}
+		cell[[2]int{s.N, s.MemMiB}] = s.EstimatedSavedMiB
+	}
+	if len(mems) < 2 {
+		return ""
+	}
+	sort.Ints(ns)
+	sort.Ints(mems)
+
+	var b strings.Builder
+	b.WriteString("## Matrix: Saved (MiB) nach N × MiB/Instanz\n\n| N \\ MiB |")
+	for _, m := range mems {
+		b.WriteString(fmt.Sprintf(" %d |", m))
+	}
+	b.WriteString("\n|---:|")
+	for range mems {
+		b.WriteString("---:|")
+	}
+	b.WriteString("\n")
+	for _, n := range ns {
+		b.WriteString(fmt.Sprintf("| %d |", n))
+		for _, m := range mems {
+			if v, ok := cell[[2]int{n, m}]; ok {
+				b.WriteString(fmt.Sprintf(" %.1f |", v))
+			} else {
+				b.WriteString(" – |")
+			}
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	return b.String()
+}
@@ -0,0 +1,67 @@
+package bench
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Speichermodi der Hogs.
+const (
+	ModeAnon       = "anon"        // anonyme Pages, Sharing nur über KSM (Default)
+	ModeFileShared = "file-shared" // Kontrollgruppe: gemeinsame Datei read-only MAP_SHARED (Page Cache)
+)
+
+// Modes listet alle gültigen Speichermodi.
+var Modes = []string{ModeAnon, ModeFileShared}
+
+// sharedFilePath ist die gemeinsame Datei eines Steps im Modus file-shared.
+func sharedFilePath(outDir string, memMiB int) string {
+	return filepath.Join(outDir, fmt.Sprintf(".density-shared-%dmib.bin", memMiB))
+}
+
+// writeSharedFile legt die gemeinsame Datei mit dem Inhaltsmuster an (MiB-weise).
+func writeSharedFile(path string, memMiB int, pattern string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	chunk := make([]byte, 1024*1024)
+	if err := FillPattern(chunk, os.Getpagesize(), pattern, 0); err != nil {
+		f.Close()
+		return err
+	}
+	for i := 0; i < memMiB; i++ {
+		if _, err := f.Write(chunk); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return f.Close()
+}
+
+// renderModeNote erklärt im Report, welcher Mechanismus im Run gemessen wurde.
+func renderModeNote(r *RunResult) string {
+	w := r.Workload
+	if w == nil || w.Mode != ModeFileShared {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("## Kontrollgruppe: Datei-Sharing (MAP_SHARED)\n\n")
+	b.WriteString("Alle Instanzen mappen dieselbe Datei read-only. Die Pages liegen genau einmal im Page Cache;\n")
+	b.WriteString("das Betriebssystem teilt sie ohne KSM. Erwartung: `pages_sharing` bleibt nahe 0,\n")
+	b.WriteString("während `pss_delta` die Ersparnis durch Datei-Sharing zeigt.\n\n")
+	b.WriteString("| N | pages_sharing (MiB) | pss_delta (MiB) |\n")
+	b.WriteString("|---:|---:|---:|\n")
+	for _, s := range r.Steps {
+		pss := "n/a"
+		if v, ok := s.Estimates[string(EstimatorPSSDelta)]; ok {
+			pss = fmt.Sprintf("%.1f", v)
+		}
+		b.WriteString(fmt.Sprintf("| %d | %.1f | %s |\n", s.N, s.Estimates[string(EstimatorPagesSharing)], pss))
+	}
+	b.WriteString("\nKSM deckt nur anonyme Pages ab (Heap, Gast-RAM von VMs); Datei-Pages teilt bereits der Page Cache.\n")
+	b.WriteString("Ersparnisse aus anderen Runs sind deshalb nur bei anonymem Speicher KSM zuzuschreiben.\n\n")
+	return b.String()
+}
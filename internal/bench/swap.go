@@ -0,0 +1,151 @@
+package bench
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/LglzNL/density/internal/ksm"
+)
+
+// DefaultTempSwapPath ist der Ort der temporären Swapdatei.
+const DefaultTempSwapPath = "/var/tmp/density-bench.swap"
+
+// SwapConfig steuert den Umgang mit Swap bei Steps, die den RAM übersteigen.
+type SwapConfig struct {
+	// Allow: Swap zählt für den Reserve-Guard mit, Steps über dem RAM laufen (langsamer) statt übersprungen zu werden.
+	Allow bool
+	// TempMiB > 0: fehlt Swap für den größten Step, wird eine temporäre Swapdatei dieser Größe
+	// angelegt und nach dem Run entfernt (explizites Opt-in, braucht root, mkswap/swapon).
+	TempMiB  int
+	TempPath string
+}
+
+// SwapInfo dokumentiert den Swap-Zustand eines Runs.
+type SwapInfo struct {
+	TotalMiB float64 `json:"total_mib"` // Swap zu Run-Beginn (ohne temporären Swap)
+	FreeMiB  float64 `json:"free_mib"`
+	// NeededMiB: so viel fehlt dem größten Step an RAM (inkl. Reserve); 0 = passt.
+	NeededMiB float64 `json:"needed_mib,omitempty"`
+	TempPath  string  `json:"temp_path,omitempty"`
+	TempMiB   int     `json:"temp_mib,omitempty"`
+	TempUsed  bool    `json:"temp_used"`         // temporärer Swap wurde angelegt
+	PeakMiB   float64 `json:"peak_used_mib"`     // max. Swap-Belegung über alle Steps
+	Warning   string  `json:"warning,omitempty"` // z.B. zu wenig Swap ohne Opt-in
+}
+
+// swapUsedMiB liefert die aktuelle Swap-Belegung (SwapTotal - SwapFree).
+func swapUsedMiB(mi map[string]uint64) float64 {
+	t, f := mi["SwapTotal"], mi["SwapFree"]
+	if t < f {
+		return 0
+	}
+	return float64(t-f) / 1024
+}
+
+// prepareSwap prüft vor dem ersten Step, ob der größte Step in RAM (minus Reserve) passt,
+// und legt bei Bedarf und Opt-in eine temporäre Swapdatei an. cleanup entfernt sie wieder.
+func prepareSwap(cfg Config, mems []int) (info *SwapInfo, cleanup func()) {
+	cleanup = func() {}
+	mi, err := ksm.ReadMemInfo()
+	if err != nil {
+		return nil, cleanup
+	}
+	info = &SwapInfo{
+		TotalMiB: float64(mi["SwapTotal"]) / 1024,
+		FreeMiB:  float64(mi["SwapFree"]) / 1024,
+	}
+
+	var peak float64
+	for _, mem := range mems {
+		for _, n := range cfg.Instances {
+			if f := cfg.workload().stepFootprintMiB(mem, n); f > peak {
+				peak = f
+			}
+		}
+	}
+	avail := float64(mi["MemAvailable"])/1024 - float64(max(cfg.ReserveMiB, 0))
+	if peak <= avail {
+		return info, cleanup
+	}
+	info.NeededMiB = peak - avail
+	if info.FreeMiB >= info.NeededMiB {
+		return info, cleanup
+	}
+	if cfg.Swap.TempMiB <= 0 {
+		info.Warning = fmt.Sprintf("größter Step braucht %.0f MiB mehr als RAM-Reserve erlaubt, nur %.0f MiB Swap frei (--temp-swap-mib für temporären Swap)", info.NeededMiB, info.FreeMiB)
+		return info, cleanup
+	}
+
+	path := cfg.Swap.TempPath
+	if path == "" {
+		path = DefaultTempSwapPath
+	}
+	info.TempPath = path
+	info.TempMiB = cfg.Swap.TempMiB
+	if err := createSwapFile(path, cfg.Swap.TempMiB); err != nil {
+		_ = os.Remove(path)
+		info.Warning = "temporärer Swap fehlgeschlagen: " + err.Error()
+		return info, cleanup
+	}
+	info.TempUsed = true
+	return info, func() {
+		if out, err := exec.Command("swapoff", path).CombinedOutput(); err != nil {
+			fmt.Fprintf(os.Stderr, "WARNUNG: swapoff %s: %v: %s\n", path, err, strings.TrimSpace(string(out)))
+			return
+		}
+		_ = os.Remove(path)
+	}
+}
+
+// createSwapFile schreibt path mit Nullen (kein fallocate: nicht jedes FS unterstützt Swap
+// auf vorallokierten Dateien), dann mkswap und swapon.
+func createSwapFile(path string, mib int) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	zero := make([]byte, 1024*1024)
+	for i := 0; i < mib; i++ {
+		if _, err := f.Write(zero); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	for _, args := range [][]string{{"mkswap", path}, {"swapon", path}} {
+		if out, err := exec.Command(args[0], args[1:]...).CombinedOutput(); err != nil {
+			return fmt.Errorf("%s: %v: %s", args[0], err, strings.TrimSpace(string(out)))
+		}
+	}
+	return nil
+}
+
+// renderSwap fasst den Swap-Zustand im Report zusammen (nur, wenn relevant).
+func renderSwap(r *RunResult) string {
+	s := r.Swap
+	if s == nil || (s.NeededMiB == 0 && s.PeakMiB == 0 && s.Warning == "") {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("## Swap\n\n")
+	b.WriteString(fmt.Sprintf("- Swap zu Run-Beginn: %.0f MiB (frei %.0f MiB)\n", s.TotalMiB, s.FreeMiB))
+	if s.NeededMiB > 0 {
+		b.WriteString(fmt.Sprintf("- Größter Step übersteigt RAM minus Reserve um %.0f MiB\n", s.NeededMiB))
+	}
+	if s.TempUsed {
+		b.WriteString(fmt.Sprintf("- Temporärer Swap: %d MiB (%s), nach dem Run entfernt\n", s.TempMiB, s.TempPath))
+	}
+	b.WriteString(fmt.Sprintf("- Max. Swap-Belegung: %.0f MiB\n", s.PeakMiB))
+	if s.Warning != "" {
+		b.WriteString("- WARNUNG: " + s.Warning + "\n")
+	}
+	if s.PeakMiB > 0 {
+		b.WriteString("\nSteps mit Swap-Belegung messen auch Swap-I/O; ausgelagerte Pages kann KSM nicht mergen.\n")
+	}
+	b.WriteString("\n")
+	return b.String()
+}
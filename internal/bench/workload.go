@@ -0,0 +1,161 @@
+package bench
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Inhaltsmuster der Hog-Pages.
+const (
+	PatternConst  = "const"  // konstantes 8-Byte-Muster (Default, maximal mergebar)
+	PatternZero   = "zero"   // Null-Pages (relevant für use_zero_pages)
+	PatternRandom = "random" // pseudozufällig, aber über alle Instanzen identisch (gleicher Seed)
+)
+
+// Patterns listet alle gültigen Inhaltsmuster.
+var Patterns = []string{PatternConst, PatternZero, PatternRandom}
+
+// HogSpec beschreibt das Speicherverhalten jeder Instanz eines Steps.
+type HogSpec struct {
+	DirtyPct float64       `json:"dirty_pct"`           // Anteil individueller Pages (0..100)
+	Redirty  time.Duration `json:"redirty,omitempty"`   // >0: individuelle Pages periodisch neu beschreiben
+	Pattern  string        `json:"pattern,omitempty"`   // Inhaltsmuster, leer = const
+	DupRatio float64       `json:"dup_ratio,omitempty"` // Anteil Instanzen mit gleichem Basis-Inhalt, 0 = alle
+
+	// Redirty-Verteilung: Goroutinen pro Instanz, Intervall-Jitter und Rate-Limit.
+	// Nullwerte = ein synchroner Burst pro Intervall (bisheriges Verhalten).
+	Workers   int     `json:"redirty_workers,omitempty"`
+	JitterPct float64 `json:"redirty_jitter_pct,omitempty"` // 0..100
+	RatePPS   int     `json:"redirty_rate_pps,omitempty"`   // Pages/s pro Instanz, 0 = unbegrenzt
+
+	// Mode: anon (Default) oder file-shared (Kontrollgruppe ohne KSM, siehe sharedfile.go).
+	Mode string `json:"mode,omitempty"`
+
+	// ForkChildren: jede Instanz forkt nach der Allokation so viele Kinder (CoW-Sharing ab Start).
+	ForkChildren int `json:"fork_children,omitempty"`
+
+	// Madvise: Hinweise, die jede Instanz nach dem Befüllen anwendet (siehe MadviseFlags).
+	Madvise []string `json:"madvise,omitempty"`
+
+	// Ballooning: Footprint der Instanz ändert sich während des Steps.
+	GrowTo   *Balloon `json:"grow_to,omitempty"`
+	ShrinkTo *Balloon `json:"shrink_to,omitempty"`
+}
+
+// SeedFor liefert den Inhalts-Seed der Instanz id von n: 0 = gemeinsamer Basis-Inhalt,
+// sonst ein instanzeigener Inhalt (nur bei DupRatio < 1).
+func (h HogSpec) SeedFor(id, n int) int {
+	if h.DupRatio <= 0 || h.DupRatio >= 1 {
+		return 0
+	}
+	shared := int(math.Ceil(h.DupRatio * float64(n)))
+	if id < shared {
+		return 0
+	}
+	return id + 1
+}
+
+// ProfileSpec liefert das Verhalten der eingebauten Profile:
+// P1: 0% unique, kein Redirty; P2: 5% unique; P3: 50% unique + Redirty (interval, Default 1s).
+func ProfileSpec(p Profile, interval time.Duration) HogSpec {
+	switch p {
+	case ProfileP2:
+		return HogSpec{DirtyPct: 5, Pattern: PatternConst}
+	case ProfileP3:
+		if interval <= 0 {
+			interval = 1 * time.Second
+		}
+		return HogSpec{DirtyPct: 50, Redirty: interval, Pattern: PatternConst}
+	default:
+		return HogSpec{DirtyPct: 0, Pattern: PatternConst}
+	}
+}
+
+// Validate prüft die Wertebereiche.
+func (h HogSpec) Validate() error {
+	if h.DirtyPct < 0 || h.DirtyPct > 100 {
+		return fmt.Errorf("dirty-pct muss 0..100 sein (ist %.2f)", h.DirtyPct)
+	}
+	if h.Redirty < 0 {
+		return fmt.Errorf("redirty muss >= 0 sein")
+	}
+	if h.DupRatio < 0 || h.DupRatio > 1 {
+		return fmt.Errorf("dup_ratio muss 0..1 sein (ist %.2f)", h.DupRatio)
+	}
+	if h.Workers < 0 {
+		return fmt.Errorf("redirty-workers muss >= 0 sein")
+	}
+	if h.JitterPct < 0 || h.JitterPct > 100 {
+		return fmt.Errorf("redirty-jitter-pct muss 0..100 sein (ist %.2f)", h.JitterPct)
+	}
+	if h.RatePPS < 0 {
+		return fmt.Errorf("redirty-rate-pps muss >= 0 sein")
+	}
+	if err := validateMadvise(h.Madvise); err != nil {
+		return err
+	}
+	if h.ForkChildren < 0 {
+		return fmt.Errorf("fork-children muss >= 0 sein")
+	}
+	switch h.Mode {
+	case "", ModeAnon:
+	case ModeFileShared:
+		if h.ForkChildren > 0 {
+			return fmt.Errorf("fork-children ist im Modus %s nicht möglich", h.Mode)
+		}
+		if h.DirtyPct > 0 || h.Redirty > 0 || h.Ballooning() {
+			return fmt.Errorf("modus %s ist read-only: dirty-pct, redirty und Ballooning sind nicht möglich", h.Mode)
+		}
+	default:
+		return fmt.Errorf("unbekannter Modus %q (%v)", h.Mode, Modes)
+	}
+	if h.Pattern != "" && !validPattern(h.Pattern) {
+		return fmt.Errorf("unbekanntes Muster %q (%v)", h.Pattern, Patterns)
+	}
+	return nil
+}
+
+func validPattern(p string) bool {
+	for _, x := range Patterns {
+		if x == p {
+			return true
+		}
+	}
+	return false
+}
+
+// FillPattern befüllt buf pageweise. Bei gleichem seed sind alle Muster über Instanzen
+// hinweg identisch; "random" variiert nur zwischen den Pages einer Instanz.
+func FillPattern(buf []byte, pageSize int, pattern string, seed uint64) error {
+	switch pattern {
+	case PatternConst, "":
+		template := make([]byte, pageSize)
+		for i := 0; i < len(template); i += 8 {
+			binary.LittleEndian.PutUint64(template[i:], 0x44454E5331545930^seed) // "DENS1TY0" als konstant
+		}
+		for off := 0; off+pageSize <= len(buf); off += pageSize {
+			copy(buf[off:off+pageSize], template)
+		}
+	case PatternZero:
+		// make() liefert bereits Nullen; einmal anfassen, damit die Pages wirklich gemappt sind.
+		for off := 0; off < len(buf); off += pageSize {
+			buf[off] = 0
+			if seed != 0 && off+8 <= len(buf) {
+				binary.LittleEndian.PutUint64(buf[off:], seed)
+			}
+		}
+	case PatternRandom:
+		x := uint64(0x9E3779B97F4A7C15) ^ (seed * 0xBF58476D1CE4E5B9) // xorshift, fester Seed
+		for i := 0; i+8 <= len(buf); i += 8 {
+			x ^= x << 13
+			x ^= x >> 7
+			x ^= x << 17
+			binary.LittleEndian.PutUint64(buf[i:], x)
+		}
+	default:
+		return fmt.Errorf("unbekanntes Muster %q", pattern)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+// Package clock abstrahiert time.Now/After/Sleep/Ticker, damit Regel- und Messschleifen
+// (Bench, Daemon) mit virtueller Zeit getestet werden können.
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock ist die Zeitquelle.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+	Sleep(d time.Duration)
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker entspricht time.Ticker.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// Real ist die echte Systemzeit.
+var Real Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (realClock) Sleep(d time.Duration)                  { time.Sleep(d) }
+func (realClock) NewTicker(d time.Duration) Ticker       { return realTicker{time.NewTicker(d)} }
+
+type realTicker struct{ t *time.Ticker }
+
+func (r realTicker) C() <-chan time.Time { return r.t.C }
+func (r realTicker) Stop()               { r.t.Stop() }
+
+// Fake ist eine manuell vorgestellte Uhr. After/Sleep/Ticker feuern erst, wenn
+// Advance die Zeit über ihren Fälligkeitszeitpunkt hinaus bewegt.
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*waiter
+	added   chan struct{}
+}
+
+type waiter struct {
+	at     time.Time
+	ch     chan time.Time
+	period time.Duration // >0 = Ticker
+}
+
+// NewFake erzeugt eine Fake-Uhr mit Startzeit start.
+func NewFake(start time.Time) *Fake {
+	return &Fake{now: start, added: make(chan struct{}, 1024)}
+}
+
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	return f.add(d, 0).ch
+}
+
+func (f *Fake) Sleep(d time.Duration) {
+	<-f.After(d)
+}
+
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+	return &fakeTicker{f: f, w: f.add(d, d)}
+}
+
+func (f *Fake) add(d, period time.Duration) *waiter {
+	f.mu.Lock()
+	w := &waiter{at: f.now.Add(d), ch: make(chan time.Time, 1), period: period}
+	if d <= 0 && period == 0 {
+		w.ch <- f.now
+	} else {
+		f.waiters = append(f.waiters, w)
+	}
+	f.mu.Unlock()
+	select {
+	case f.added <- struct{}{}:
+	default:
+	}
+	return w
+}
+
+// Advance stellt die Uhr um d vor und feuert alle fälligen Timer/Ticker in Zeitreihenfolge.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	target := f.now.Add(d)
+	for {
+		sort.Slice(f.waiters, func(i, j int) bool { return f.waiters[i].at.Before(f.waiters[j].at) })
+		if len(f.waiters) == 0 || f.waiters[0].at.After(target) {
+			break
+		}
+		w := f.waiters[0]
+		f.now = w.at
+		select {
+		case w.ch <- w.at:
+		default: // Ticker-Semantik: verpasste Ticks werden verworfen
+		}
+		if w.period > 0 {
+			w.at = w.at.Add(w.period)
+		} else {
+			f.waiters = f.waiters[1:]
+		}
+	}
+	f.now = target
+	f.mu.Unlock()
+}
+
+// BlockUntil wartet, bis mindestens n Timer/Ticker auf die Uhr warten.
+// Damit können Tests sicherstellen, dass der Code unter Test "schläft", bevor sie Advance aufrufen.
+func (f *Fake) BlockUntil(n int) {
+	for {
+		f.mu.Lock()
+		c := len(f.waiters)
+		f.mu.Unlock()
+		if c >= n {
+			return
+		}
+		<-f.added
+	}
+}
+
+type fakeTicker struct {
+	f *Fake
+	w *waiter
+}
+
+func (t *fakeTicker) C() <-chan time.Time { return t.w.ch }
+
+func (t *fakeTicker) Stop() {
+	t.f.mu.Lock()
+	defer t.f.mu.Unlock()
+	for i, w := range t.f.waiters {
+		if w == t.w {
+			t.f.waiters = append(t.f.waiters[:i], t.f.waiters[i+1:]...)
+			return
+		}
+	}
+}
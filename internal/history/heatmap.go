@@ -0,0 +1,172 @@
+package history
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/LglzNL/density/internal/ksm"
+)
+
+// Metric wählt die Größe, die in der Heatmap aggregiert wird.
+type Metric string
+
+const (
+	MetricSaved   Metric = "saved"    // Ersparnis in MiB
+	MetricKsmdCPU Metric = "ksmd-cpu" // ksmd-CPU in % eines Cores
+)
+
+// Heatmap sind Mittelwerte je Wochentag (Mo=0) und Stunde; NaN = keine Daten.
+type Heatmap struct {
+	Metric Metric         `json:"metric"`
+	Cells  [7][24]float64 `json:"-"`
+	Counts [7][24]int     `json:"counts"`
+}
+
+// BuildHeatmap aggregiert Samples in lokaler Zeit. Für ksmd-cpu wird die Tick-Differenz
+// zum vorherigen Sample herangezogen (Lücken > maxGap werden ignoriert).
+func BuildHeatmap(samples []Sample, m Metric, loc *time.Location) (*Heatmap, error) {
+	if m != MetricSaved && m != MetricKsmdCPU {
+		return nil, fmt.Errorf("unbekannte Metrik %q (saved|ksmd-cpu)", m)
+	}
+	if loc == nil {
+		loc = time.Local
+	}
+	const maxGap = 15 * time.Minute
+
+	h := &Heatmap{Metric: m}
+	var sums [7][24]float64
+	for i, s := range samples {
+		var v float64
+		switch m {
+		case MetricSaved:
+			v = s.SavedMiB
+		case MetricKsmdCPU:
+			if i == 0 {
+				continue
+			}
+			prev := samples[i-1]
+			dt := s.Time.Sub(prev.Time)
+			if dt <= 0 || dt > maxGap || prev.KsmdTicks == 0 || s.KsmdTicks < prev.KsmdTicks {
+				continue
+			}
+			v = float64(s.KsmdTicks-prev.KsmdTicks) / ksm.ClockTicksPerSec / dt.Seconds() * 100
+		}
+		t := s.Time.In(loc)
+		d := (int(t.Weekday()) + 6) % 7 // Montag zuerst
+		sums[d][t.Hour()] += v
+		h.Counts[d][t.Hour()]++
+	}
+	for d := 0; d < 7; d++ {
+		for hr := 0; hr < 24; hr++ {
+			if h.Counts[d][hr] == 0 {
+				h.Cells[d][hr] = math.NaN()
+				continue
+			}
+			h.Cells[d][hr] = sums[d][hr] / float64(h.Counts[d][hr])
+		}
+	}
+	return h, nil
+}
+
+var weekdays = [7]string{"Mo", "Di", "Mi", "Do", "Fr", "Sa", "So"}
+
+// max liefert den größten Zellwert (NaN-Zellen ignoriert).
+func (h *Heatmap) max() float64 {
+	var mx float64
+	for d := range h.Cells {
+		for _, v := range h.Cells[d] {
+			if !math.IsNaN(v) && v > mx {
+				mx = v
+			}
+		}
+	}
+	return mx
+}
+
+// RenderASCII zeichnet die Heatmap mit Schattierungen (für Terminals).
+func (h *Heatmap) RenderASCII() string {
+	shades := []rune(" ░▒▓█")
+	mx := h.max()
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("%s nach Wochentag/Stunde (max %.2f %s)\n\n    ", h.title(), mx, h.unit()))
+	for hr := 0; hr < 24; hr += 3 {
+		b.WriteString(fmt.Sprintf("%-6s", fmt.Sprintf("%02d", hr)))
+	}
+	b.WriteString("\n")
+	for d := 0; d < 7; d++ {
+		b.WriteString(weekdays[d] + "  ")
+		for hr := 0; hr < 24; hr++ {
+			v := h.Cells[d][hr]
+			if math.IsNaN(v) {
+				b.WriteString("··")
+				continue
+			}
+			idx := 0
+			if mx > 0 {
+				idx = int(math.Round(v / mx * float64(len(shades)-1)))
+			}
+			b.WriteString(strings.Repeat(string(shades[idx]), 2))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(fmt.Sprintf("\nLegende: ' ' = 0, '█' = %.2f %s, '··' = keine Daten\n", mx, h.unit()))
+	return b.String()
+}
+
+// RenderMarkdown rendert die Mittelwerte als Tabelle (für Reports/Digest).
+func (h *Heatmap) RenderMarkdown() string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("### %s (%s) nach Wochentag/Stunde\n\n| Tag |", h.title(), h.unit()))
+	for hr := 0; hr < 24; hr++ {
+		b.WriteString(fmt.Sprintf(" %02d |", hr))
+	}
+	b.WriteString("\n|---|")
+	for hr := 0; hr < 24; hr++ {
+		b.WriteString("---:|")
+	}
+	b.WriteString("\n")
+	for d := 0; d < 7; d++ {
+		b.WriteString("| " + weekdays[d] + " |")
+		for hr := 0; hr < 24; hr++ {
+			v := h.Cells[d][hr]
+			if math.IsNaN(v) {
+				b.WriteString(" – |")
+				continue
+			}
+			b.WriteString(fmt.Sprintf(" %.1f |", v))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Grid liefert die Zellen JSON-tauglich (nil = keine Daten), z.B. für Zeitplan-Policies.
+func (h *Heatmap) Grid() [7][24]*float64 {
+	var g [7][24]*float64
+	for d := range h.Cells {
+		for hr, v := range h.Cells[d] {
+			if !math.IsNaN(v) {
+				v := v
+				g[d][hr] = &v
+			}
+		}
+	}
+	return g
+}
+
+func (h *Heatmap) title() string {
+	if h.Metric == MetricKsmdCPU {
+		return "ksmd-CPU"
+	}
+	return "Ersparnis"
+}
+
+func (h *Heatmap) unit() string {
+	if h.Metric == MetricKsmdCPU {
+		return "% Core"
+	}
+	return "MiB"
+}
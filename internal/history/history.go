@@ -0,0 +1,99 @@
+// Package history speichert Zeitreihen-Samples des Hosts (KSM-Stats, MemAvailable,
+// ksmd-Kosten) als JSON Lines, damit spätere Auswertungen (Heatmaps, Digest) darauf aufbauen.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/LglzNL/density/internal/ksm"
+)
+
+// DefaultPath ist der Default-Speicherort der Sample-Historie.
+const DefaultPath = "/var/lib/density/history.jsonl"
+
+// Sample ist ein Messpunkt.
+type Sample struct {
+	Time           time.Time        `json:"t"`
+	KSM            map[string]int64 `json:"ksm,omitempty"`
+	MemAvailableKB uint64           `json:"mem_available_kb,omitempty"`
+	SavedMiB       float64          `json:"saved_mib"`
+	KsmdTicks      int64            `json:"ksmd_ticks,omitempty"`
+}
+
+// Collect nimmt einen Sample vom laufenden System.
+func Collect(ksmPath string) Sample {
+	s := Sample{Time: time.Now()}
+	s.KSM, _ = ksm.Status(ksmPath)
+	if mi, err := ksm.ReadMemInfo(); err == nil {
+		s.MemAvailableKB = mi["MemAvailable"]
+	}
+	s.SavedMiB = savedMiB(s.KSM)
+	s.KsmdTicks, _ = ksm.ReadKsmdTicks()
+	return s
+}
+
+func savedMiB(st map[string]int64) float64 {
+	shared, sharing := st["pages_shared"], st["pages_sharing"]
+	if shared <= 0 || sharing < shared {
+		return 0
+	}
+	return float64(sharing-shared) * float64(os.Getpagesize()) / (1024.0 * 1024.0)
+}
+
+// Append hängt Samples an die Historie an (Datei/Verzeichnis werden angelegt).
+func Append(path string, samples ...Sample) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, s := range samples {
+		if err := enc.Encode(s); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Read liest alle Samples ab since (Zero = alle). Defekte Zeilen (z.B. abgebrochener
+// letzter Write) werden übersprungen; eine fehlende Datei ergibt eine leere Historie.
+func Read(path string, since time.Time) ([]Sample, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var out []Sample
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		var s Sample
+		if err := json.Unmarshal(sc.Bytes(), &s); err != nil {
+			continue
+		}
+		if !since.IsZero() && s.Time.Before(since) {
+			continue
+		}
+		out = append(out, s)
+	}
+	return out, sc.Err()
+}
@@ -0,0 +1,149 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// DefaultKnobsPath ist der Default-Speicherort des Knob-Änderungsprotokolls.
+const DefaultKnobsPath = "/var/lib/density/knobs.jsonl"
+
+// Quellen einer Knob-Änderung.
+const (
+	SourceDrift    = "drift"    // extern geändert, beim Sampling erkannt
+	SourceObserved = "observed" // erstmals beobachtet, vorheriger Wert unbekannt
+)
+
+// KnobChange ist eine einzelne Änderung eines KSM-Knobs.
+type KnobChange struct {
+	Time   time.Time `json:"t"`
+	Knob   string    `json:"knob"`
+	Old    *int64    `json:"old,omitempty"` // nil = vorher unbekannt
+	New    int64     `json:"new"`
+	Source string    `json:"source"` // z.B. "density:enable" oder "drift"
+}
+
+// DiffKnobs liefert die Änderungen von before nach after (sortiert nach Knob).
+// Knobs, die in before fehlen, werden mit unbekanntem Altwert gemeldet.
+func DiffKnobs(before, after map[string]int64, source string, t time.Time) []KnobChange {
+	var out []KnobChange
+	for k, nv := range after {
+		ov, ok := before[k]
+		if ok && ov == nv {
+			continue
+		}
+		c := KnobChange{Time: t, Knob: k, New: nv, Source: source}
+		if ok {
+			ov := ov
+			c.Old = &ov
+		}
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Knob < out[j].Knob })
+	return out
+}
+
+// AppendKnobChanges hängt Änderungen an das Protokoll an.
+func AppendKnobChanges(path string, changes ...KnobChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	for _, c := range changes {
+		if err := enc.Encode(c); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return f.Close()
+}
+
+// ReadKnobChanges liest das Protokoll (ab since; Zero = alles).
+func ReadKnobChanges(path string, since time.Time) ([]KnobChange, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var out []KnobChange
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var c KnobChange
+		if err := json.Unmarshal(sc.Bytes(), &c); err != nil {
+			continue
+		}
+		if !since.IsZero() && c.Time.Before(since) {
+			continue
+		}
+		out = append(out, c)
+	}
+	return out, sc.Err()
+}
+
+// KnobStateAt rekonstruiert den Knob-Stand zum Zeitpunkt t aus dem Protokoll.
+func KnobStateAt(changes []KnobChange, t time.Time) map[string]int64 {
+	state := map[string]int64{}
+	for _, c := range changes {
+		if c.Time.After(t) {
+			break
+		}
+		state[c.Knob] = c.New
+	}
+	return state
+}
+
+// SavedAround liefert die mittlere Ersparnis im Fenster vor bzw. nach t.
+// ok=false, wenn auf einer Seite keine Samples liegen.
+func SavedAround(samples []Sample, t time.Time, window time.Duration) (before, after float64, ok bool) {
+	var sb, sa float64
+	var nb, na int
+	for _, s := range samples {
+		switch {
+		case !s.Time.After(t) && s.Time.After(t.Add(-window)):
+			sb += s.SavedMiB
+			nb++
+		case s.Time.After(t) && !s.Time.After(t.Add(window)):
+			sa += s.SavedMiB
+			na++
+		}
+	}
+	if nb == 0 || na == 0 {
+		return 0, 0, false
+	}
+	return sb / float64(nb), sa / float64(na), true
+}
+
+// DetectDrift vergleicht die Knobs eines Samples mit dem protokollierten Stand
+// und liefert Änderungen, die nicht von DENSITY selbst protokolliert wurden.
+func DetectDrift(known map[string]int64, s Sample, knobs []string) []KnobChange {
+	cur := map[string]int64{}
+	for _, k := range knobs {
+		if v, ok := s.KSM[k]; ok {
+			cur[k] = v
+		}
+	}
+	changes := DiffKnobs(known, cur, SourceDrift, s.Time)
+	for i := range changes {
+		if changes[i].Old == nil {
+			changes[i].Source = SourceObserved
+		}
+	}
+	return changes
+}
@@ -0,0 +1,289 @@
+// Package importer überführt Fremd- und Altdaten (stress-ng, ksmtuned, sysfs-CSV)
+// in das bench.RunResult-Schema, damit sie in denselben Reports vergleichbar sind.
+package importer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LglzNL/density/internal/bench"
+)
+
+// Formate, die Import versteht.
+const (
+	FormatSysfsCSV = "sysfs-csv"
+	FormatKsmtuned = "ksmtuned"
+	FormatStressNG = "stress-ng"
+)
+
+// Formats listet alle unterstützten Formate (für Hilfe-Texte).
+var Formats = []string{FormatSysfsCSV, FormatKsmtuned, FormatStressNG}
+
+// Import liest r im angegebenen Format. profile wird in Run und Steps übernommen,
+// da Fremddaten kein DENSITY-Profil kennen.
+func Import(format string, r io.Reader, profile bench.Profile) (*bench.RunResult, error) {
+	var (
+		res *bench.RunResult
+		err error
+	)
+	switch format {
+	case FormatSysfsCSV:
+		res, err = importSysfsCSV(r)
+	case FormatKsmtuned:
+		res, err = importKsmtuned(r)
+	case FormatStressNG:
+		res, err = importStressNG(r)
+	default:
+		return nil, fmt.Errorf("unbekanntes Import-Format %q (%s)", format, strings.Join(Formats, "|"))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(res.Steps) == 0 {
+		return nil, fmt.Errorf("%s: keine verwertbaren Datensätze gefunden", format)
+	}
+
+	res.Source = format
+	res.Profile = profile
+	for i := range res.Steps {
+		res.Steps[i].Profile = profile
+	}
+	return res, nil
+}
+
+// memInfoKeys sind CSV-Spalten, die als /proc/meminfo-Werte (kB) interpretiert werden.
+var memInfoKeys = map[string]bool{
+	"MemTotal":     true,
+	"MemFree":      true,
+	"MemAvailable": true,
+	"SwapTotal":    true,
+	"SwapFree":     true,
+}
+
+// importSysfsCSV erwartet eine Kopfzeile. Erkannte Spalten:
+//   - timestamp|time (RFC3339 oder Unix-Sekunden)
+//   - n|instances, alive, mem_mib
+//   - MemTotal/MemFree/MemAvailable/SwapTotal/SwapFree (kB) -> PostMemKB
+//   - alle übrigen numerischen Spalten (pages_sharing, ...) -> PostKSM
+//
+// Jede Zeile wird zu einem Step.
+func importSysfsCSV(r io.Reader) (*bench.RunResult, error) {
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("sysfs-csv: Kopfzeile fehlt: %w", err)
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+
+	res := &bench.RunResult{}
+	var prev time.Time
+	line := 1
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("sysfs-csv: Zeile %d: %w", line, err)
+		}
+
+		step := bench.StepResult{
+			PostMemKB: map[string]uint64{},
+			PostKSM:   map[string]int64{},
+		}
+		var ts time.Time
+		for i, raw := range rec {
+			if i >= len(header) {
+				break
+			}
+			col, val := header[i], strings.TrimSpace(raw)
+			if val == "" {
+				continue
+			}
+			switch strings.ToLower(col) {
+			case "timestamp", "time":
+				ts, err = parseTimestamp(val)
+				if err != nil {
+					return nil, fmt.Errorf("sysfs-csv: Zeile %d, Spalte %q: %w", line, col, err)
+				}
+				continue
+			case "n", "instances":
+				step.N, err = strconv.Atoi(val)
+			case "alive":
+				step.Alive, err = strconv.Atoi(val)
+			case "mem_mib":
+				step.MemMiB, err = strconv.Atoi(val)
+			default:
+				var v int64
+				v, err = strconv.ParseInt(val, 10, 64)
+				if err == nil {
+					if memInfoKeys[col] {
+						step.PostMemKB[col] = uint64(v)
+					} else {
+						step.PostKSM[col] = v
+					}
+				}
+			}
+			if err != nil {
+				return nil, fmt.Errorf("sysfs-csv: Zeile %d, Spalte %q: ungültiger Wert %q", line, col, val)
+			}
+		}
+
+		if !ts.IsZero() {
+			if res.StartedAt.IsZero() {
+				res.StartedAt = ts
+			}
+			if !prev.IsZero() && ts.After(prev) {
+				res.Steps[len(res.Steps)-1].Duration = ts.Sub(prev)
+			}
+			prev = ts
+		}
+		if len(step.PostMemKB) == 0 {
+			step.PostMemKB = nil
+		}
+		if len(step.PostKSM) == 0 {
+			step.PostKSM = nil
+		}
+		step.EstimatedSavedMiB = bench.EstimateSavedMiB(step.PostKSM)
+		res.Steps = append(res.Steps, step)
+	}
+	return res, nil
+}
+
+func parseTimestamp(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if sec, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Unix(0, int64(sec*float64(time.Second))).UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("unbekanntes Zeitformat %q", s)
+}
+
+var (
+	// "Mon Jan  1 12:00:00 UTC 2024: committed 123 free 456"
+	ksmtunedLine      = regexp.MustCompile(`^(.+?\d{4}): (.*)$`)
+	ksmtunedCommitted = regexp.MustCompile(`^committed (\d+) free (\d+)`)
+	ksmtunedCtl       = regexp.MustCompile(`^KSMCTL (start|stop)(?: (\d+) (\d+))?`)
+)
+
+// importKsmtuned liest ksmtuned-Debuglogs (DEBUG=1, /var/log/ksmtuned).
+// Jede "committed/free"-Messung wird ein Step; die darauffolgende KSMCTL-Zeile
+// liefert run, pages_to_scan und sleep_millisecs. Werte sind in kB.
+func importKsmtuned(r io.Reader) (*bench.RunResult, error) {
+	res := &bench.RunResult{}
+	sc := bufio.NewScanner(r)
+	var cur *bench.StepResult
+	var curTS time.Time
+	flush := func() {
+		if cur != nil {
+			res.Steps = append(res.Steps, *cur)
+			cur = nil
+		}
+	}
+
+	for sc.Scan() {
+		m := ksmtunedLine.FindStringSubmatch(strings.TrimSpace(sc.Text()))
+		if m == nil {
+			continue
+		}
+		ts, _ := time.Parse(time.UnixDate, strings.Join(strings.Fields(m[1]), " "))
+		msg := m[2]
+
+		if c := ksmtunedCommitted.FindStringSubmatch(msg); c != nil {
+			flush()
+			committed, _ := strconv.ParseUint(c[1], 10, 64)
+			free, _ := strconv.ParseUint(c[2], 10, 64)
+			if !ts.IsZero() {
+				if res.StartedAt.IsZero() {
+					res.StartedAt = ts
+				}
+				if len(res.Steps) > 0 && !curTS.IsZero() && ts.After(curTS) {
+					res.Steps[len(res.Steps)-1].Duration = ts.Sub(curTS)
+				}
+				curTS = ts
+			}
+			cur = &bench.StepResult{
+				PostMemKB: map[string]uint64{"MemFree": free},
+				Notes:     fmt.Sprintf("ksmtuned: committed=%d kB", committed),
+			}
+			continue
+		}
+		if c := ksmtunedCtl.FindStringSubmatch(msg); c != nil && cur != nil {
+			stats := map[string]int64{"run": 0}
+			if c[1] == "start" {
+				stats["run"] = 1
+				if c[2] != "" {
+					stats["pages_to_scan"], _ = strconv.ParseInt(c[2], 10, 64)
+					stats["sleep_millisecs"], _ = strconv.ParseInt(c[3], 10, 64)
+				}
+			}
+			cur.PostKSM = stats
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+	return res, nil
+}
+
+var (
+	stressNGDispatch  = regexp.MustCompile(`dispatching hogs: (.+)$`)
+	stressNGVMHogs    = regexp.MustCompile(`(\d+) vm\b`)
+	stressNGCompleted = regexp.MustCompile(`(successful|unsuccessful) run completed in ([\d.]+)\s*s`)
+)
+
+// importStressNG liest stress-ng-Logs (z.B. "stress-ng --vm N --ksm ... 2>&1 | tee log").
+// Jede Invocation ("dispatching hogs" ... "run completed") wird ein Step mit N = Anzahl vm-Hogs.
+// KSM-Stats enthält der Log nicht; dafür parallel eine sysfs-CSV aufzeichnen.
+func importStressNG(r io.Reader) (*bench.RunResult, error) {
+	res := &bench.RunResult{}
+	sc := bufio.NewScanner(r)
+	var cur *bench.StepResult
+
+	for sc.Scan() {
+		line := sc.Text()
+		if m := stressNGDispatch.FindStringSubmatch(line); m != nil {
+			if cur != nil {
+				res.Steps = append(res.Steps, *cur)
+			}
+			cur = &bench.StepResult{Notes: "stress-ng: " + strings.TrimSpace(m[1])}
+			if vm := stressNGVMHogs.FindStringSubmatch(m[1]); vm != nil {
+				cur.N, _ = strconv.Atoi(vm[1])
+			}
+			continue
+		}
+		if m := stressNGCompleted.FindStringSubmatch(line); m != nil && cur != nil {
+			secs, _ := strconv.ParseFloat(m[2], 64)
+			cur.Duration = time.Duration(secs * float64(time.Second))
+			if m[1] == "successful" {
+				cur.Alive = cur.N
+			} else {
+				cur.Notes += "; Lauf nicht erfolgreich"
+			}
+			res.Steps = append(res.Steps, *cur)
+			cur = nil
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if cur != nil {
+		cur.Notes += "; kein Laufende im Log"
+		res.Steps = append(res.Steps, *cur)
+	}
+	return res, nil
+}
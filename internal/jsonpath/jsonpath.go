@@ -0,0 +1,199 @@
+// Package jsonpath implementiert eine kleine Teilmenge von JSONPath (kubectl-Stil),
+// damit Shell-Skripte auf minimalen Hosts ohne jq auskommen.
+//
+// Unterstützt: {.a.b}, {.steps[0].n}, {.steps[-1].n}, {.steps[*].n}, {['key']}.
+package jsonpath
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type segment struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// Eval wertet expr gegen v aus. v darf ein beliebiger (JSON-serialisierbarer) Wert sein;
+// er wird intern über JSON normalisiert, damit Tags/Feldnamen wie im Output gelten.
+func Eval(v any, expr string) ([]any, error) {
+	segs, err := parse(expr)
+	if err != nil {
+		return nil, err
+	}
+	root, err := normalize(v)
+	if err != nil {
+		return nil, err
+	}
+
+	cur := []any{root}
+	for _, s := range segs {
+		var next []any
+		for _, c := range cur {
+			out, err := step(c, s)
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, out...)
+		}
+		cur = next
+	}
+	return cur, nil
+}
+
+// Field ist die Kurzform für ein einzelnes Top-Level-Feld (--field name).
+func Field(v any, name string) ([]any, error) {
+	if name == "" {
+		return nil, fmt.Errorf("leerer Feldname")
+	}
+	return Eval(v, "{['"+name+"']}")
+}
+
+// Format rendert Ergebnisse für die Shell: Skalare roh, Objekte/Arrays als kompaktes JSON,
+// mehrere Treffer durch Leerzeichen getrennt (wie kubectl).
+func Format(vals []any) string {
+	parts := make([]string, 0, len(vals))
+	for _, v := range vals {
+		switch t := v.(type) {
+		case string:
+			parts = append(parts, t)
+		case nil:
+			parts = append(parts, "null")
+		case json.Number:
+			parts = append(parts, t.String())
+		case bool:
+			parts = append(parts, strconv.FormatBool(t))
+		default:
+			b, _ := json.Marshal(t)
+			parts = append(parts, string(b))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func normalize(v any) (any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(strings.NewReader(string(b)))
+	dec.UseNumber() // int64-Werte aus sysfs nicht als float64 verfälschen
+	var out any
+	if err := dec.Decode(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func step(v any, s segment) ([]any, error) {
+	switch {
+	case s.wildcard:
+		switch t := v.(type) {
+		case []any:
+			return t, nil
+		case map[string]any:
+			out := make([]any, 0, len(t))
+			for _, k := range sortedKeys(t) {
+				out = append(out, t[k])
+			}
+			return out, nil
+		}
+		return nil, fmt.Errorf("[*] auf Nicht-Container angewendet")
+	case s.isIndex:
+		arr, ok := v.([]any)
+		if !ok {
+			return nil, fmt.Errorf("[%d] auf Nicht-Array angewendet", s.index)
+		}
+		i := s.index
+		if i < 0 {
+			i += len(arr)
+		}
+		if i < 0 || i >= len(arr) {
+			return nil, fmt.Errorf("Index %d außerhalb des Bereichs (Länge %d)", s.index, len(arr))
+		}
+		return []any{arr[i]}, nil
+	default:
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("Feld %q auf Nicht-Objekt angewendet", s.key)
+		}
+		val, ok := m[s.key]
+		if !ok {
+			return nil, fmt.Errorf("Feld %q nicht gefunden", s.key)
+		}
+		return []any{val}, nil
+	}
+}
+
+func parse(expr string) ([]segment, error) {
+	e := strings.TrimSpace(expr)
+	if strings.HasPrefix(e, "{") {
+		if !strings.HasSuffix(e, "}") {
+			return nil, fmt.Errorf("ungültiger jsonpath %q: fehlende '}'", expr)
+		}
+		e = e[1 : len(e)-1]
+	}
+	e = strings.TrimPrefix(e, "$")
+
+	var segs []segment
+	for i := 0; i < len(e); {
+		switch e[i] {
+		case '.':
+			i++
+			j := i
+			for j < len(e) && e[j] != '.' && e[j] != '[' {
+				j++
+			}
+			if j == i {
+				if j >= len(e) && len(segs) == 0 {
+					// "{.}" = gesamtes Dokument
+					return segs, nil
+				}
+				return nil, fmt.Errorf("ungültiger jsonpath %q: leerer Feldname an Position %d", expr, i)
+			}
+			segs = append(segs, segment{key: e[i:j]})
+			i = j
+		case '[':
+			j := strings.IndexByte(e[i:], ']')
+			if j < 0 {
+				return nil, fmt.Errorf("ungültiger jsonpath %q: fehlende ']'", expr)
+			}
+			inner := strings.TrimSpace(e[i+1 : i+j])
+			i += j + 1
+			switch {
+			case inner == "*":
+				segs = append(segs, segment{wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				segs = append(segs, segment{key: inner[1 : len(inner)-1]})
+			default:
+				n, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("ungültiger jsonpath %q: Index %q", expr, inner)
+				}
+				segs = append(segs, segment{index: n, isIndex: true})
+			}
+		default:
+			// erlaubt "steps[0].n" ohne führenden Punkt
+			if len(segs) == 0 && i == 0 {
+				e = "." + e
+				continue
+			}
+			return nil, fmt.Errorf("ungültiger jsonpath %q: unerwartetes Zeichen %q an Position %d", expr, e[i], i)
+		}
+	}
+	return segs, nil
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,162 @@
+package ksm
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/LglzNL/density/internal/clock"
+)
+
+// FaultsEnv ist die Umgebungsvariable, über die Fault-Injection aktiviert wird, z.B.
+//
+//	DENSITY_FAULTS="write_fail=0.2,read_fail=0.05,slow_read=200ms,partial_stats=0.3,seed=42"
+//
+// Nur für Tests/Chaos-Experimente gedacht – niemals auf Produktivhosts setzen.
+const FaultsEnv = "DENSITY_FAULTS"
+
+// Faults beschreibt injizierte Fehler im sysfs-Zugriff. Wahrscheinlichkeiten 0..1.
+type Faults struct {
+	WriteFail    float64
+	ReadFail     float64
+	SlowRead     time.Duration
+	PartialStats float64 // Wahrscheinlichkeit, mit der Status() ein Feld verschluckt
+	Seed         int64
+
+	Clock clock.Clock // für SlowRead; nil = clock.Real
+}
+
+var (
+	faultsMu sync.Mutex
+	faults   *Faults
+	faultRng *rand.Rand
+)
+
+// SetFaults aktiviert (oder mit nil deaktiviert) die Fault-Injection prozessweit.
+func SetFaults(f *Faults) {
+	faultsMu.Lock()
+	defer faultsMu.Unlock()
+	faults = f
+	if f != nil {
+		seed := f.Seed
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		faultRng = rand.New(rand.NewSource(seed))
+	}
+}
+
+// FaultsFromEnv liest FaultsEnv; nil, wenn nicht gesetzt.
+func FaultsFromEnv() (*Faults, error) {
+	spec := strings.TrimSpace(os.Getenv(FaultsEnv))
+	if spec == "" {
+		return nil, nil
+	}
+	return ParseFaults(spec)
+}
+
+// ParseFaults parst "key=value,..." (write_fail, read_fail, slow_read, partial_stats, seed).
+func ParseFaults(spec string) (*Faults, error) {
+	f := &Faults{}
+	for _, kv := range strings.Split(spec, ",") {
+		kv = strings.TrimSpace(kv)
+		if kv == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			return nil, fmt.Errorf("faults: %q: erwartet key=value", kv)
+		}
+		var err error
+		switch k {
+		case "write_fail":
+			f.WriteFail, err = parseProb(v)
+		case "read_fail":
+			f.ReadFail, err = parseProb(v)
+		case "partial_stats":
+			f.PartialStats, err = parseProb(v)
+		case "slow_read":
+			f.SlowRead, err = time.ParseDuration(v)
+		case "seed":
+			f.Seed, err = strconv.ParseInt(v, 10, 64)
+		default:
+			return nil, fmt.Errorf("faults: unbekannter Schlüssel %q", k)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("faults: %s: %v", k, err)
+		}
+	}
+	return f, nil
+}
+
+func parseProb(s string) (float64, error) {
+	p, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	if p < 0 || p > 1 {
+		return 0, fmt.Errorf("Wahrscheinlichkeit %v außerhalb 0..1", p)
+	}
+	return p, nil
+}
+
+// String gibt die Konfiguration im ParseFaults-Format zurück.
+func (f *Faults) String() string {
+	return fmt.Sprintf("write_fail=%g,read_fail=%g,slow_read=%s,partial_stats=%g,seed=%d",
+		f.WriteFail, f.ReadFail, f.SlowRead, f.PartialStats, f.Seed)
+}
+
+// ErrInjected markiert künstlich erzeugte Fehler.
+type ErrInjected struct{ Op, Path string }
+
+func (e *ErrInjected) Error() string {
+	return fmt.Sprintf("injizierter Fehler (%s %s)", e.Op, e.Path)
+}
+
+// roll liefert true mit Wahrscheinlichkeit p (nur bei aktiver Injection).
+func roll(p float64) bool {
+	faultsMu.Lock()
+	defer faultsMu.Unlock()
+	return faults != nil && p > 0 && faultRng.Float64() < p
+}
+
+func activeFaults() *Faults {
+	faultsMu.Lock()
+	defer faultsMu.Unlock()
+	return faults
+}
+
+func injectRead(p string) error {
+	f := activeFaults()
+	if f == nil {
+		return nil
+	}
+	if f.SlowRead > 0 {
+		c := f.Clock
+		if c == nil {
+			c = clock.Real
+		}
+		c.Sleep(f.SlowRead)
+	}
+	if roll(f.ReadFail) {
+		return &ErrInjected{Op: "read", Path: p}
+	}
+	return nil
+}
+
+func injectWrite(p string) error {
+	f := activeFaults()
+	if f != nil && roll(f.WriteFail) {
+		return &ErrInjected{Op: "write", Path: p}
+	}
+	return nil
+}
+
+func injectPartial() bool {
+	f := activeFaults()
+	return f != nil && roll(f.PartialStats)
+}
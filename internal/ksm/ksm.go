@@ -119,7 +119,7 @@ func Status(path string) (map[string]int64, error) {
 		}
 		name := e.Name()
 		val, err := readInt(filepath.Join(path, name))
-		if err != nil {
+		if err != nil || injectPartial() {
 			continue
 		}
 		out[name] = val
@@ -148,6 +148,9 @@ func WriteInt(path, name string, v int64) error {
 }
 
 func readInt(p string) (int64, error) {
+	if err := injectRead(p); err != nil {
+		return 0, err
+	}
 	b, err := os.ReadFile(p)
 	if err != nil {
 		return 0, err
@@ -157,6 +160,9 @@ func readInt(p string) (int64, error) {
 }
 
 func writeInt(p string, v int64) error {
+	if err := injectWrite(p); err != nil {
+		return err
+	}
 	// sysfs existiert bereits; perms sind irrelevant, aber müssen gesetzt sein für WriteFile.
 	return os.WriteFile(p, []byte(strconv.FormatInt(v, 10)), fs.FileMode(0o644))
 }
@@ -201,3 +207,20 @@ func ReadMemInfo() (map[string]uint64, error) {
 	}
 	return out, nil
 }
+
+// Knobs sind die numerischen Tuning-Dateien, deren Änderungen DENSITY verfolgt.
+var Knobs = []string{
+	"run", "pages_to_scan", "sleep_millisecs", "merge_across_nodes",
+	"max_page_sharing", "use_zero_pages", "stable_node_chains_prune_millisecs",
+}
+
+// ReadKnobs liest alle vorhandenen Knobs (fehlende werden ausgelassen).
+func ReadKnobs(path string) map[string]int64 {
+	out := make(map[string]int64, len(Knobs))
+	for _, k := range Knobs {
+		if v, err := ReadInt(path, k); err == nil {
+			out[k] = v
+		}
+	}
+	return out
+}
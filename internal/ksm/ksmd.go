@@ -0,0 +1,85 @@
+package ksm
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ClockTicksPerSec ist USER_HZ; auf Linux praktisch immer 100.
+const ClockTicksPerSec = 100
+
+// ReadKsmdTicks liefert utime+stime (Clock-Ticks) des ksmd-Kernel-Threads.
+func ReadKsmdTicks() (int64, error) {
+	pid, err := findPIDByComm("ksmd")
+	if err != nil {
+		return 0, err
+	}
+	statPath := filepath.Join("/proc", strconv.Itoa(pid), "stat")
+	b, err := os.ReadFile(statPath)
+	if err != nil {
+		return 0, err
+	}
+	// /proc/[pid]/stat: fields are space-separated, but field 2 can contain spaces in parentheses.
+	// We'll parse carefully.
+	utime, stime, err := parseProcStatUtimeStime(string(b))
+	if err != nil {
+		return 0, err
+	}
+	return utime + stime, nil
+}
+
+func findPIDByComm(comm string) (int, error) {
+	d, err := os.ReadDir("/proc")
+	if err != nil {
+		return 0, err
+	}
+	for _, e := range d {
+		if !e.IsDir() {
+			continue
+		}
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		cpath := filepath.Join("/proc", e.Name(), "comm")
+		b, err := os.ReadFile(cpath)
+		if err != nil {
+			continue
+		}
+		name := strings.TrimSpace(string(b))
+		if name == comm {
+			return pid, nil
+		}
+	}
+	return 0, fmt.Errorf("process %q nicht gefunden", comm)
+}
+
+func parseProcStatUtimeStime(stat string) (int64, int64, error) {
+	// Find the last ')' which ends comm field.
+	i := strings.LastIndex(stat, ")")
+	if i < 0 {
+		return 0, 0, fmt.Errorf("unexpected /proc/stat format")
+	}
+	after := strings.Fields(stat[i+1:])
+	// utime is field 14, stime field 15 in the original format.
+	// After stripping pid+comm, the offset changes.
+	// Original fields:
+	// 1 pid, 2 comm, 3 state, 4 ppid, ... 14 utime, 15 stime
+	// After comm removed, after[0] = state (field 3).
+	// Thus utime (14) -> after index (14-3) = 11, stime -> 12
+	if len(after) < 13 {
+		return 0, 0, fmt.Errorf("unexpected /proc/stat fields")
+	}
+	ut, err := strconv.ParseInt(after[11], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	st, err := strconv.ParseInt(after[12], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	return ut, st, nil
+}
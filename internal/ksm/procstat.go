@@ -0,0 +1,42 @@
+package ksm
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ReadProcessStat liest /proc/<pid>/ksm_stat (pid 0 = eigener Prozess).
+// Numerische Felder landen als Zahl, ja/nein-Felder (ksm_mergeable, ksm_merge_any) als 1/0.
+// Ältere Kernel (< 6.1) haben die Datei nicht; dann wird ein Fehler geliefert.
+func ReadProcessStat(pid int) (map[string]int64, error) {
+	p := "self"
+	if pid > 0 {
+		p = strconv.Itoa(pid)
+	}
+	b, err := os.ReadFile(filepath.Join("/proc", p, "ksm_stat"))
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]int64{}
+	for _, line := range strings.Split(string(b), "\n") {
+		k, v, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		k = strings.TrimSuffix(k, ":")
+		v = strings.TrimSpace(v)
+		switch v {
+		case "yes":
+			out[k] = 1
+		case "no":
+			out[k] = 0
+		default:
+			if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+				out[k] = n
+			}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,55 @@
+package results
+
+import (
+	"sort"
+
+	"github.com/LglzNL/density/internal/stats"
+)
+
+// StepAggregate fasst die Ersparnis eines N über mehrere Runs zusammen.
+type StepAggregate struct {
+	N          int              `json:"n"`
+	Samples    int              `json:"samples"`
+	Rejected   []float64        `json:"rejected,omitempty"`
+	Summary    stats.Summary    `json:"summary"`
+	Confidence stats.Confidence `json:"confidence"`
+	Unstable   bool             `json:"unstable"`
+}
+
+// AggregateOptions steuert Ausreißererkennung und Stabilitätsschwelle.
+type AggregateOptions struct {
+	OutlierZ float64 // 0 = stats.DefaultOutlierZ
+	MaxCV    float64 // 0 = stats.DefaultMaxCV
+}
+
+// Aggregate gruppiert die Steps aller Einträge nach N, verwirft Ausreißer
+// (modifizierter z-Score) und annotiert den Rest mit Konfidenz/Instabilität.
+func Aggregate(entries []Entry, opt AggregateOptions) []StepAggregate {
+	if opt.MaxCV <= 0 {
+		opt.MaxCV = stats.DefaultMaxCV
+	}
+	byN := map[int][]float64{}
+	for _, e := range entries {
+		for _, s := range e.Result.Steps {
+			if s.N > 0 && !s.Skipped {
+				byN[s.N] = append(byN[s.N], s.EstimatedSavedMiB)
+			}
+		}
+	}
+
+	out := make([]StepAggregate, 0, len(byN))
+	for n, xs := range byN {
+		kept, rejected := stats.RejectOutliers(xs, opt.OutlierZ)
+		sum := stats.Summarize(kept)
+		out = append(out, StepAggregate{
+			N:          n,
+			Samples:    len(xs),
+			Rejected:   rejected,
+			Summary:    sum,
+			Confidence: sum.Confidence(),
+			Unstable:   sum.Unstable(opt.MaxCV),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].N < out[j].N })
+	return out
+}
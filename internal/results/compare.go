@@ -0,0 +1,200 @@
+package results
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/LglzNL/density/internal/bench"
+)
+
+// Normalization legt fest, worauf die Ersparnis bezogen wird.
+type Normalization string
+
+const (
+	NormAbsolute    Normalization = "absolute"     // MiB
+	NormPerInstance Normalization = "per-instance" // MiB pro Instanz
+	NormPerGiBInst  Normalization = "per-gib"      // MiB pro GiB Instanz-RAM
+	NormPerCore     Normalization = "per-core"     // MiB pro Host-Core
+	NormPctHost     Normalization = "pct-host"     // % des Host-RAM
+)
+
+// Normalizations listet alle bekannten Normalisierungen in Ausgabereihenfolge.
+var Normalizations = []Normalization{NormAbsolute, NormPerInstance, NormPerGiBInst, NormPerCore, NormPctHost}
+
+// hostTolerance: bis zu dieser relativen Abweichung gelten Hosts als gleich groß.
+const hostTolerance = 0.10
+
+// CompareRow ist ein Step-Paar (gleiches N) mit normalisierten Werten je Normalisierung.
+type CompareRow struct {
+	N      int
+	A, B   map[Normalization]float64
+	MemMiB [2]int
+}
+
+// Comparison ist das Ergebnis von Compare.
+type Comparison struct {
+	A, B           Entry
+	Normalizations []Normalization
+	Rows           []CompareRow
+	// Comparable=false: die Runs sind grundsätzlich nicht vergleichbar (Gründe in Warnings).
+	Comparable bool
+	Warnings   []string
+}
+
+// Compare stellt zwei Runs gegenüber. Ist norms leer, werden passende Normalisierungen
+// automatisch gewählt: absolute Werte nur bei gleich großen Hosts und gleicher Instanzgröße,
+// sonst die jeweils bereinigten Kennzahlen.
+func Compare(a, b Entry, norms []Normalization) Comparison {
+	c := Comparison{A: a, B: b, Comparable: true}
+	ra, rb := a.Result, b.Result
+
+	if ra.Profile != rb.Profile {
+		c.Comparable = false
+		c.Warnings = append(c.Warnings, fmt.Sprintf("unterschiedliche Profile (%s vs %s): Workloads sind nicht vergleichbar", ra.Profile, rb.Profile))
+	}
+	ha, hb := hostOf(ra), hostOf(rb)
+	if ha.PageSize != 0 && hb.PageSize != 0 && ha.PageSize != hb.PageSize {
+		c.Comparable = false
+		c.Warnings = append(c.Warnings, fmt.Sprintf("unterschiedliche Page-Größen (%d vs %d)", ha.PageSize, hb.PageSize))
+	}
+
+	sameMem := ha.MemTotalKB > 0 && hb.MemTotalKB > 0 && within(float64(ha.MemTotalKB), float64(hb.MemTotalKB), hostTolerance)
+	sameCPU := ha.CPUs > 0 && ha.CPUs == hb.CPUs
+	sameInst := sameInstanceSize(ra, rb)
+
+	if len(norms) == 0 {
+		if ha.MemTotalKB == 0 || hb.MemTotalKB == 0 {
+			c.Warnings = append(c.Warnings, "Hostgröße in mindestens einem Run unbekannt: nur instanzbezogene Normalisierung möglich")
+		}
+		if sameMem && sameInst {
+			norms = append(norms, NormAbsolute)
+		}
+		norms = append(norms, NormPerInstance)
+		if !sameInst {
+			norms = append(norms, NormPerGiBInst)
+		}
+		if !sameMem && ha.MemTotalKB > 0 && hb.MemTotalKB > 0 {
+			norms = append(norms, NormPctHost)
+		}
+		if !sameCPU && ha.CPUs > 0 && hb.CPUs > 0 {
+			norms = append(norms, NormPerCore)
+		}
+	}
+	c.Normalizations = norms
+
+	for _, n := range norms {
+		switch n {
+		case NormPerCore:
+			if ha.CPUs == 0 || hb.CPUs == 0 {
+				c.Warnings = append(c.Warnings, "per-core: CPU-Anzahl fehlt in mindestens einem Run")
+			}
+		case NormPctHost:
+			if ha.MemTotalKB == 0 || hb.MemTotalKB == 0 {
+				c.Warnings = append(c.Warnings, "pct-host: MemTotal fehlt in mindestens einem Run")
+			}
+		case NormAbsolute:
+			if !sameMem {
+				c.Warnings = append(c.Warnings, "absolute Werte bei unterschiedlich großen Hosts sind nur eingeschränkt aussagekräftig")
+			}
+		}
+	}
+
+	byN := make(map[int]bench.StepResult, len(rb.Steps))
+	for _, s := range rb.Steps {
+		if s.N > 0 && !s.Skipped {
+			byN[s.N] = s
+		}
+	}
+	for _, sa := range ra.Steps {
+		sb, ok := byN[sa.N]
+		if sa.N <= 0 || sa.Skipped || !ok {
+			continue
+		}
+		row := CompareRow{
+			N:      sa.N,
+			A:      map[Normalization]float64{},
+			B:      map[Normalization]float64{},
+			MemMiB: [2]int{sa.MemMiB, sb.MemMiB},
+		}
+		for _, n := range norms {
+			row.A[n] = normalize(n, sa, ha)
+			row.B[n] = normalize(n, sb, hb)
+		}
+		c.Rows = append(c.Rows, row)
+	}
+	if len(c.Rows) == 0 {
+		c.Comparable = false
+		c.Warnings = append(c.Warnings, "keine gemeinsamen Instanzzahlen (N) in beiden Runs")
+	}
+	return c
+}
+
+func normalize(n Normalization, s bench.StepResult, h bench.HostInfo) float64 {
+	saved := s.EstimatedSavedMiB
+	switch n {
+	case NormAbsolute:
+		return saved
+	case NormPerInstance:
+		if s.N > 0 {
+			return saved / float64(s.N)
+		}
+	case NormPerGiBInst:
+		if gib := float64(s.N*s.MemMiB) / 1024.0; gib > 0 {
+			return saved / gib
+		}
+	case NormPerCore:
+		if h.CPUs > 0 {
+			return saved / float64(h.CPUs)
+		}
+	case NormPctHost:
+		if h.MemTotalKB > 0 {
+			return saved * 1024.0 / float64(h.MemTotalKB) * 100.0
+		}
+	}
+	return math.NaN()
+}
+
+// hostOf liefert HostInfo; ältere Runs ohne Host-Block fallen auf MemTotal aus meminfo zurück.
+func hostOf(r *bench.RunResult) bench.HostInfo {
+	if r.Host != nil {
+		return *r.Host
+	}
+	var h bench.HostInfo
+	for _, s := range r.Steps {
+		if v := s.PreMemKB["MemTotal"]; v > 0 {
+			h.MemTotalKB = v
+			break
+		}
+	}
+	return h
+}
+
+func sameInstanceSize(a, b *bench.RunResult) bool {
+	sizes := map[int]bool{}
+	for _, s := range a.Steps {
+		sizes[s.MemMiB] = true
+	}
+	for _, s := range b.Steps {
+		if !sizes[s.MemMiB] {
+			return false
+		}
+	}
+	return true
+}
+
+func within(a, b, tol float64) bool {
+	if a == 0 || b == 0 {
+		return a == b
+	}
+	return math.Abs(a-b)/math.Max(a, b) <= tol
+}
+
+// ParseNormalization validiert einen Normalisierungsnamen aus der CLI.
+func ParseNormalization(s string) (Normalization, error) {
+	for _, n := range Normalizations {
+		if string(n) == s {
+			return n, nil
+		}
+	}
+	return "", fmt.Errorf("unbekannte Normalisierung %q", s)
+}
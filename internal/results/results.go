@@ -0,0 +1,157 @@
+// Package results findet und filtert Bench-Ergebnisse (bench_*.json) in einem Results-Verzeichnis.
+package results
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LglzNL/density/internal/bench"
+)
+
+// Entry ist ein geladenes Ergebnis-File plus ein paar vorberechnete Kennzahlen.
+type Entry struct {
+	Path     string
+	Result   *bench.RunResult
+	MaxN     int
+	MaxSaved float64
+}
+
+// Filter schränkt die Auswahl ein. Nullwerte bedeuten "kein Filter".
+type Filter struct {
+	Profile bench.Profile
+	Since   time.Duration // nur Runs, die jünger sind
+	MinN    int           // mindestens ein Step mit N >= MinN
+	MaxN    int           // kein Step mit N > MaxN
+}
+
+// Scan lädt rekursiv alle bench_*.json unterhalb von dir.
+// Nicht lesbare oder nicht parsebare Files werden übersprungen.
+func Scan(dir string) ([]Entry, error) {
+	var out []Entry
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		name := d.Name()
+		if !strings.HasPrefix(name, "bench_") || !strings.HasSuffix(name, ".json") {
+			return nil
+		}
+		e, err := Load(p)
+		if err != nil {
+			return nil
+		}
+		out = append(out, e)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Load liest ein einzelnes Ergebnis-File.
+func Load(path string) (Entry, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Entry{}, err
+	}
+	var r bench.RunResult
+	if err := json.Unmarshal(b, &r); err != nil {
+		return Entry{}, fmt.Errorf("%s: %w", path, err)
+	}
+	e := Entry{Path: path, Result: &r}
+	for _, s := range r.Steps {
+		if s.Skipped {
+			continue
+		}
+		if s.N > e.MaxN {
+			e.MaxN = s.N
+		}
+		if s.EstimatedSavedMiB > e.MaxSaved {
+			e.MaxSaved = s.EstimatedSavedMiB
+		}
+	}
+	return e, nil
+}
+
+// Apply gibt die Einträge zurück, die f erfüllen (relativ zu now).
+func Apply(entries []Entry, f Filter, now time.Time) []Entry {
+	var out []Entry
+	for _, e := range entries {
+		if f.Profile != "" && !strings.EqualFold(string(e.Result.Profile), string(f.Profile)) {
+			continue
+		}
+		if f.Since > 0 && e.Result.StartedAt.Before(now.Add(-f.Since)) {
+			continue
+		}
+		if f.MinN > 0 && e.MaxN < f.MinN {
+			continue
+		}
+		if f.MaxN > 0 && e.MaxN > f.MaxN {
+			continue
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+// Sort sortiert in-place nach key: "started" (default), "saved", "n", "path".
+// desc=true sortiert absteigend.
+func Sort(entries []Entry, key string, desc bool) error {
+	var less func(a, b Entry) bool
+	switch key {
+	case "", "started":
+		less = func(a, b Entry) bool { return a.Result.StartedAt.Before(b.Result.StartedAt) }
+	case "saved":
+		less = func(a, b Entry) bool { return a.MaxSaved < b.MaxSaved }
+	case "n":
+		less = func(a, b Entry) bool { return a.MaxN < b.MaxN }
+	case "path":
+		less = func(a, b Entry) bool { return a.Path < b.Path }
+	default:
+		return fmt.Errorf("unbekannter Sortierschlüssel %q (started|saved|n|path)", key)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if desc {
+			return less(entries[j], entries[i])
+		}
+		return less(entries[i], entries[j])
+	})
+	return nil
+}
+
+// ParseAge parst Altersangaben wie "30d", "2w", "12h" oder "90m".
+// Zusätzlich zu time.ParseDuration werden Tage (d) und Wochen (w) unterstützt.
+func ParseAge(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	unit := s[len(s)-1]
+	if unit == 'd' || unit == 'w' {
+		n, err := strconv.ParseFloat(s[:len(s)-1], 64)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("ungültige Altersangabe %q", s)
+		}
+		day := 24 * time.Hour
+		if unit == 'w' {
+			day *= 7
+		}
+		return time.Duration(n * float64(day)), nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("ungültige Altersangabe %q", s)
+	}
+	return d, nil
+}
@@ -0,0 +1,166 @@
+// Package stats enthält die kleinen Statistik-Helfer für wiederholte Messungen:
+// Kennzahlen, Konfidenzintervalle und Ausreißererkennung (modifizierter z-Score).
+package stats
+
+import (
+	"math"
+	"sort"
+)
+
+// DefaultOutlierZ ist die übliche Schwelle für den modifizierten z-Score (Iglewicz/Hoaglin).
+const DefaultOutlierZ = 3.5
+
+// DefaultMaxCV: oberhalb dieses Variationskoeffizienten gilt eine Messreihe als instabil.
+const DefaultMaxCV = 0.20
+
+// Confidence beschreibt grob, wie belastbar ein Mittelwert ist.
+type Confidence string
+
+const (
+	ConfidenceHigh   Confidence = "high"
+	ConfidenceMedium Confidence = "medium"
+	ConfidenceLow    Confidence = "low"
+)
+
+// Summary fasst eine Messreihe zusammen. CI95 ist die halbe Breite des
+// 95%-Konfidenzintervalls des Mittelwerts (t-Verteilung).
+type Summary struct {
+	N      int     `json:"n"`
+	Mean   float64 `json:"mean"`
+	Stddev float64 `json:"stddev"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	CI95   float64 `json:"ci95"`
+}
+
+// Summarize berechnet Summary (Stichproben-Standardabweichung, n-1).
+func Summarize(xs []float64) Summary {
+	s := Summary{N: len(xs)}
+	if len(xs) == 0 {
+		return s
+	}
+	s.Min, s.Max = xs[0], xs[0]
+	var sum float64
+	for _, x := range xs {
+		sum += x
+		s.Min = math.Min(s.Min, x)
+		s.Max = math.Max(s.Max, x)
+	}
+	s.Mean = sum / float64(len(xs))
+	if len(xs) < 2 {
+		return s
+	}
+	var sq float64
+	for _, x := range xs {
+		sq += (x - s.Mean) * (x - s.Mean)
+	}
+	s.Stddev = math.Sqrt(sq / float64(len(xs)-1))
+	s.CI95 = tCritical95(len(xs)-1) * s.Stddev / math.Sqrt(float64(len(xs)))
+	return s
+}
+
+// CV ist der Variationskoeffizient (Stddev/|Mean|); 0 bei Mean=0.
+func (s Summary) CV() float64 {
+	if s.Mean == 0 {
+		return 0
+	}
+	return s.Stddev / math.Abs(s.Mean)
+}
+
+// Confidence leitet aus Stichprobengröße und Streuung eine grobe Einstufung ab.
+func (s Summary) Confidence() Confidence {
+	cv := s.CV()
+	switch {
+	case s.N >= 5 && cv <= 0.05:
+		return ConfidenceHigh
+	case s.N >= 3 && cv <= 0.15:
+		return ConfidenceMedium
+	default:
+		return ConfidenceLow
+	}
+}
+
+// Unstable meldet, ob die Streuung maxCV überschreitet (Mittelwert wäre irreführend).
+func (s Summary) Unstable(maxCV float64) bool {
+	return s.N >= 2 && s.CV() > maxCV
+}
+
+// Median der Werte (Eingabe wird nicht verändert).
+func Median(xs []float64) float64 {
+	if len(xs) == 0 {
+		return 0
+	}
+	c := append([]float64(nil), xs...)
+	sort.Float64s(c)
+	m := len(c) / 2
+	if len(c)%2 == 1 {
+		return c[m]
+	}
+	return (c[m-1] + c[m]) / 2
+}
+
+// ModifiedZScores liefert 0.6745*(x-median)/MAD je Wert. Ist MAD=0 (mehr als die
+// Hälfte der Werte identisch), wird auf die mittlere absolute Abweichung ausgewichen.
+func ModifiedZScores(xs []float64) []float64 {
+	out := make([]float64, len(xs))
+	if len(xs) < 3 {
+		return out
+	}
+	med := Median(xs)
+	dev := make([]float64, len(xs))
+	var meanAD float64
+	for i, x := range xs {
+		dev[i] = math.Abs(x - med)
+		meanAD += dev[i]
+	}
+	meanAD /= float64(len(xs))
+
+	if mad := Median(dev); mad > 0 {
+		for i, x := range xs {
+			out[i] = 0.6745 * (x - med) / mad
+		}
+		return out
+	}
+	if meanAD > 0 {
+		for i, x := range xs {
+			out[i] = (x - med) / (1.253314 * meanAD)
+		}
+	}
+	return out
+}
+
+// RejectOutliers trennt Werte mit |modifiziertem z-Score| > z ab.
+// Unter 3 Werten wird nichts verworfen.
+func RejectOutliers(xs []float64, z float64) (kept, rejected []float64) {
+	if z <= 0 {
+		z = DefaultOutlierZ
+	}
+	scores := ModifiedZScores(xs)
+	for i, x := range xs {
+		if math.Abs(scores[i]) > z {
+			rejected = append(rejected, x)
+			continue
+		}
+		kept = append(kept, x)
+	}
+	return kept, rejected
+}
+
+// tCritical95 liefert den zweiseitigen 95%-Wert der t-Verteilung für df Freiheitsgrade.
+func tCritical95(df int) float64 {
+	table := []float64{0, 12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262,
+		2.228, 2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093,
+		2.086, 2.080, 2.074, 2.069, 2.064, 2.060, 2.056, 2.052, 2.048, 2.045, 2.042}
+	switch {
+	case df <= 0:
+		return 0
+	case df < len(table):
+		return table[df]
+	case df < 60:
+		return 2.000
+	case df < 120:
+		return 1.980
+	default:
+		return 1.960
+	}
+}